@@ -0,0 +1,79 @@
+/*
+Copyright © 2024 NAME HERE <EMAIL ADDRESS>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/nicksrepo/padawanzero/verifier"
+	"github.com/spf13/cobra"
+)
+
+var verifyAddressFile string
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify a serialized address offline",
+	Long: `Verify checks the key and commitment encodings, the ZKP and the nonce
+hash structure of a JSON encoded AddressInfo without any network or storage
+access, for use in air-gapped audit workflows.
+
+The ZKP carries its own group parameters, which are validated before the
+proof is checked against the address public key. For example:
+
+PadawanZero verify --address address.json`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(verifyAddressFile)
+		if err != nil {
+			return err
+		}
+
+		report, err := verifier.VerifyJSON(data)
+		if err != nil {
+			return err
+		}
+
+		for _, check := range report.Checks {
+			if check.Err != nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "FAIL  %s: %v\n", check.Name, check.Err)
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "OK    %s\n", check.Name)
+			}
+		}
+
+		if !report.Valid() {
+			return errors.New("address verification failed")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().StringVar(&verifyAddressFile, "address", "", "JSON encoded address to verify")
+	verifyCmd.MarkFlagRequired("address")
+}
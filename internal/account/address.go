@@ -3,9 +3,10 @@ package account
 import (
 	"bytes"
 	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"math/big"
 	"sync"
 
 	"github.com/nicksrepo/padawanzero/internal/common"
@@ -33,9 +34,49 @@ type NetworkAddress struct {
 	ZKP                *libzk13.ZK13 `json:"-"`
 	PrivateKey         kyber.Scalar  `json:"-"`
 	PublicKey          kyber.Point   `json:"public_key"`
-	r, P               *big.Int
+	zkpProof           *libzk13.KnowledgeProof
 	Suite              kyber.Group
 	Nonce              *state.Nonce
+	QuantumKeys        *QuantumKeys `json:"-"`
+	CommitmentOpening  *QuantumKeys `json:"-"`
+}
+
+// QuantumKeys holds a Kyber key pair together with the ciphertext of the
+// shared secret used to derive an Edwards25519 point from it.
+type QuantumKeys struct {
+	PublicKey  []byte
+	SecretKey  []byte
+	Ciphertext []byte
+}
+
+// Point recovers the Edwards25519 point derived from the quantum keys.
+func (qk *QuantumKeys) Point() (kyber.Point, error) {
+	return common.QuantumRecoverEdwardsPoint(qk.SecretKey, qk.Ciphertext)
+}
+
+// Scalar recovers the discrete logarithm of the point derived from the quantum keys.
+func (qk *QuantumKeys) Scalar() (kyber.Scalar, error) {
+	return common.QuantumRecoverEdwardsScalar(qk.SecretKey, qk.Ciphertext)
+}
+
+// newQuantumKeys generates a Kyber key pair and derives an Edwards25519 point from it.
+func newQuantumKeys() (*QuantumKeys, kyber.Point, error) {
+	quantumPublicKey, quantumPrivateKey, err := common.GenerateQuantumKeyPair()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate quantum key pair: %v", err)
+	}
+
+	point, ciphertext, err := common.QuantumEncapsulateEdwardsPoint(quantumPublicKey, quantumPrivateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to derive Edwards point: %v", err)
+	}
+
+	qk := &QuantumKeys{
+		PublicKey:  quantumPublicKey,
+		SecretKey:  quantumPrivateKey,
+		Ciphertext: ciphertext,
+	}
+	return qk, point, nil
 }
 
 // AddressInfo provides a serializable and usable representation of NetworkAddress.
@@ -47,6 +88,15 @@ type AddressInfo struct {
 	NonceHash          string
 }
 
+// AddressID identifies an address by the hex encoded Blake3 hash of its public key.
+type AddressID string
+
+// ID returns the AddressID of the AddressInfo.
+func (ai *AddressInfo) ID() AddressID {
+	sum := blake3.Sum256([]byte(ai.PublicKey))
+	return AddressID(hex.EncodeToString(sum[:]))
+}
+
 var (
 	suitePool = sync.Pool{
 		New: func() interface{} {
@@ -81,28 +131,31 @@ func putAddressInfo(ai *AddressInfo) {
 }
 
 func GenerateCryptoKeys() (kyber.Group, kyber.Scalar, kyber.Point, error) {
+	suite, classicalPrivateKey, combinedPublicKey, _, err := generateHybridKeys()
+	return suite, classicalPrivateKey, combinedPublicKey, err
+}
+
+// generateHybridKeys generates the classical and quantum keys of an address and
+// also returns the quantum keys needed to recompute the combined public key.
+func generateHybridKeys() (kyber.Group, kyber.Scalar, kyber.Point, *QuantumKeys, error) {
 	suite := edwards25519.NewBlakeSHA256Ed25519()
 
 	// Generate classical keys
 	classicalPrivateKey := suite.Scalar().Pick(suite.RandomStream())
-	classicalPublicKey := suite.Point().Mul(classicalPrivateKey, nil)
-
-	// Generate quantum keys
-	quantumPublicKey, quantumPrivateKey, err := common.GenerateQuantumKeyPair()
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to generate quantum key pair: %v", err)
-	}
 
-	// Derive an Edwards25519 point from the quantum keys
-	quantumDerivedPoint, err := common.QuantumDeriveEdwardsPoint(quantumPublicKey, quantumPrivateKey)
+	// Generate quantum keys and derive an Edwards25519 point from them
+	quantumKeys, quantumDerivedPoint, err := newQuantumKeys()
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to derive Edwards point: %v", err)
+		return nil, nil, nil, nil, err
 	}
 
-	// Combine classical and quantum-derived public keys
-	combinedPublicKey := suite.Point().Add(classicalPublicKey, quantumDerivedPoint)
+	return suite, classicalPrivateKey, CombinePublicKey(suite, classicalPrivateKey, quantumDerivedPoint), quantumKeys, nil
+}
 
-	return suite, classicalPrivateKey, combinedPublicKey, nil
+// CombinePublicKey combines the classical public key of privateKey with a quantum-derived point.
+func CombinePublicKey(suite kyber.Group, privateKey kyber.Scalar, quantumDerivedPoint kyber.Point) kyber.Point {
+	classicalPublicKey := suite.Point().Mul(privateKey, nil)
+	return suite.Point().Add(classicalPublicKey, quantumDerivedPoint)
 }
 
 // NewNetworkAddress initializes a NetworkAddress with given latitude and longitude.
@@ -115,7 +168,7 @@ func NewNetworkAddress(lat, lon float64) (*NetworkAddress, error) {
 		return nil, fmt.Errorf("invalid longitude: %f, must be between -180 and 180", lon)
 	}
 
-	suite, privateKey, publicKey, err := GenerateCryptoKeys()
+	suite, privateKey, publicKey, quantumKeys, err := generateHybridKeys()
 	if err != nil {
 		return nil, fmt.Errorf("error generating crypto keys: %w", err)
 	}
@@ -135,7 +188,7 @@ func NewNetworkAddress(lat, lon float64) (*NetworkAddress, error) {
 		return nil, fmt.Errorf("error converting anon geo location to bytes: %w", err)
 	}
 
-	_, locationCommitment, err := CommitLocation(privateKey, anonGeoBytes)
+	locationCommitment, opening, err := commitLocation(privateKey, anonGeoBytes)
 	if err != nil {
 		return nil, fmt.Errorf("error creating location commitment: %w", err)
 	}
@@ -150,12 +203,21 @@ func NewNetworkAddress(lat, lon float64) (*NetworkAddress, error) {
 		PublicKey:          publicKey,
 		Suite:              suite,
 		Nonce:              n,
+		QuantumKeys:        quantumKeys,
+		CommitmentOpening:  opening,
 	}
 
 	return na, nil
 }
 
-// GenerateZKP generates a Zero-Knowledge Proof for the NetworkAddress.
+// zkpContext binds the ZKP of an address to its encoded public key, so the
+// proof cannot be copied onto another address.
+func zkpContext(publicKey string) []byte {
+	return []byte(publicKey)
+}
+
+// GenerateZKP generates a Zero-Knowledge Proof for the NetworkAddress. The
+// proof is publicly verifiable and bound to the address public key.
 func (na *NetworkAddress) GenerateZKP(bits int) error {
 	if na.AnonGeoLocation == nil || len(na.AnonGeoLocation) == 0 {
 		return fmt.Errorf("AnonGeoLocation is empty. Cannot generate ZKP")
@@ -166,10 +228,20 @@ func (na *NetworkAddress) GenerateZKP(bits int) error {
 	h.Write([]byte(secretBaggage))
 	hash := h.Sum(nil)
 
+	if na.PublicKey == nil {
+		return fmt.Errorf("PublicKey is empty. Cannot generate ZKP")
+	}
+	publicKeyBytes, err := na.PublicKey.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
 	na.ZKP = libzk13.NewZK13(string(hash), bits)
-	r, _ := na.ZKP.Prover(new(big.Int).SetBytes(hash))
-	na.r = r.R
-	na.P = r.P
+	proof, err := na.ZKP.ProveKnowledge(zkpContext(base64.RawStdEncoding.EncodeToString(publicKeyBytes)))
+	if err != nil {
+		return fmt.Errorf("error generating ZKP: %w", err)
+	}
+	na.zkpProof = proof
 
 	pretty.Logf("zkp: %v", na.ZKP)
 
@@ -199,7 +271,7 @@ func GenerateAddress(lat, lon float64, bits int) (*AddressInfo, error) {
 	wg.Add(4)
 
 	var publicKey, locationCommitment kyber.Point
-	var zkpProofStr string
+	var zkp *libzk13.ZK13
 	var nonce *state.Nonce
 	var errs [4]error
 
@@ -237,9 +309,7 @@ func GenerateAddress(lat, lon float64, bits int) (*AddressInfo, error) {
 		h := blake3.New()
 		h.Write([]byte(fmt.Sprintf("%f,%f", lat, lon)))
 		hash := h.Sum(nil)
-		zkp := libzk13.NewZK13(string(hash), bits)
-		r, _ := zkp.Prover(new(big.Int).SetBytes(hash))
-		zkpProofStr = r.R.Text(16) + "|" + r.P.Text(16)
+		zkp = libzk13.NewZK13(string(hash), bits)
 	}()
 
 	go func() {
@@ -259,11 +329,18 @@ func GenerateAddress(lat, lon float64, bits int) (*AddressInfo, error) {
 
 	publicKeyBytes, _ := publicKey.MarshalBinary()
 	locationCommitmentBytes, _ := locationCommitment.MarshalBinary()
+	encodedPublicKey := base64.RawStdEncoding.EncodeToString(publicKeyBytes)
+
+	// The proof is bound to the public key, so it is made once both are ready
+	zkpProof, err := zkp.ProveKnowledge(zkpContext(encodedPublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("error generating ZKP: %w", err)
+	}
 
 	ai := &AddressInfo{
-		PublicKey:          base64.RawStdEncoding.EncodeToString(publicKeyBytes),
+		PublicKey:          encodedPublicKey,
 		LocationCommitment: base64.RawStdEncoding.EncodeToString(locationCommitmentBytes),
-		ZKPProof:           zkpProofStr,
+		ZKPProof:           zkpProof.String(),
 		NonceValue:         base64.StdEncoding.EncodeToString(nonce.Value),
 		NonceHash:          base64.StdEncoding.EncodeToString(nonce.Hash),
 	}
@@ -299,17 +376,52 @@ func GenerateAddressesBatch(coords [][2]float64, bits int) ([]*AddressInfo, erro
 	return addresses, nil
 }
 
+// addressInfoBinaryVersion is the current version of the AddressInfo binary codec.
+// Legacy encodings, which separate fields with zero bytes, never start with it.
+const addressInfoBinaryVersion = 1
+
+// MarshalBinary encodes the AddressInfo as a version byte followed by
+// length-prefixed fields.
 func (ai *AddressInfo) MarshalBinary() ([]byte, error) {
 	buf := make([]byte, 0, 1024)
-	buf = append(buf, []byte(ai.PublicKey)...)
-	buf = append(buf, 0) // separator
-	buf = append(buf, []byte(ai.LocationCommitment)...)
-	buf = append(buf, 0) // separator
-	buf = append(buf, []byte(ai.ZKPProof)...)
+	buf = append(buf, addressInfoBinaryVersion)
+	for _, field := range []string{ai.PublicKey, ai.LocationCommitment, ai.ZKPProof, ai.NonceValue, ai.NonceHash} {
+		buf = binary.AppendUvarint(buf, uint64(len(field)))
+		buf = append(buf, field...)
+	}
 	return buf, nil
 }
 
+// UnmarshalBinary decodes data produced by MarshalBinary. It also accepts the
+// legacy zero-separated encoding without nonce fields.
 func (ai *AddressInfo) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != addressInfoBinaryVersion {
+		return ai.unmarshalLegacyBinary(data)
+	}
+
+	data = data[1:]
+	fields := make([]string, 5)
+	for i := range fields {
+		n, read := binary.Uvarint(data)
+		if read <= 0 || n > uint64(len(data)-read) {
+			return errors.New("invalid binary format")
+		}
+		fields[i] = string(data[read : read+int(n)])
+		data = data[read+int(n):]
+	}
+	if len(data) != 0 {
+		return errors.New("invalid binary format")
+	}
+
+	ai.PublicKey = fields[0]
+	ai.LocationCommitment = fields[1]
+	ai.ZKPProof = fields[2]
+	ai.NonceValue = fields[3]
+	ai.NonceHash = fields[4]
+	return nil
+}
+
+func (ai *AddressInfo) unmarshalLegacyBinary(data []byte) error {
 	parts := bytes.Split(data, []byte{0})
 	if len(parts) != 3 {
 		return errors.New("invalid binary format")
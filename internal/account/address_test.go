@@ -7,6 +7,8 @@ import (
 	"runtime"
 	"sync"
 	"testing"
+
+	libzk13 "github.com/nicksrepo/padawanzero/zero-knowledge"
 )
 
 func TestGenerateCryptoKeys(t *testing.T) {
@@ -60,8 +62,15 @@ func TestGenerateZKP(t *testing.T) {
 	err = na.GenerateZKP(256)
 	assert.NoError(t, err)
 	assert.NotNil(t, na.ZKP)
-	assert.NotNil(t, na.r)
-	assert.NotNil(t, na.P)
+	require.NotNil(t, na.zkpProof)
+
+	// The proof in the AddressInfo verifies against its public key
+	ai, err := na.Info()
+	require.NoError(t, err)
+	proof, err := libzk13.ParseKnowledgeProof(ai.ZKPProof)
+	require.NoError(t, err)
+	assert.NoError(t, libzk13.VerifyKnowledge(proof, []byte(ai.PublicKey)))
+	assert.Error(t, libzk13.VerifyKnowledge(proof, []byte("another key")))
 
 	// Test with empty AnonGeoLocation
 	naEmpty := &NetworkAddress{}
@@ -126,6 +135,37 @@ func TestAddressInfoMarshalUnmarshal(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestAddressInfoMarshalUnmarshalBinary(t *testing.T) {
+	ai := &AddressInfo{
+		PublicKey:          "testPublicKey",
+		LocationCommitment: "testLocationCommitment",
+		ZKPProof:           "testZKPProof",
+		NonceValue:         "testNonceValue",
+		NonceHash:          "testNonceHash",
+	}
+
+	data, err := ai.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, byte(addressInfoBinaryVersion), data[0])
+
+	aiNew := &AddressInfo{}
+	err = aiNew.UnmarshalBinary(data)
+	assert.NoError(t, err)
+	assert.Equal(t, ai, aiNew)
+
+	// Legacy zero-separated encoding
+	aiLegacy := &AddressInfo{}
+	err = aiLegacy.UnmarshalBinary([]byte("testPublicKey\x00testLocationCommitment\x00testZKPProof"))
+	assert.NoError(t, err)
+	assert.Equal(t, ai.PublicKey, aiLegacy.PublicKey)
+	assert.Equal(t, ai.ZKPProof, aiLegacy.ZKPProof)
+	assert.Empty(t, aiLegacy.NonceValue)
+
+	// Truncated and trailing data
+	assert.Error(t, aiNew.UnmarshalBinary(data[:len(data)-1]))
+	assert.Error(t, aiNew.UnmarshalBinary(append(data, 0)))
+}
+
 func BenchmarkGenerateAddress(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -0,0 +1,160 @@
+package account
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DIDMethodPrefix prefixes the DIDs of addresses: did:padawan:<AddressID>.
+const DIDMethodPrefix = "did:padawan:"
+
+// SchnorrVerificationKeyType is the verification method type of address keys.
+// Addresses sign with kyber Schnorr signatures over Edwards25519 with a
+// SHA-256 challenge, which RFC 8032 Ed25519 verifiers reject, so the key is
+// not published as an Ed25519 key. Signatures are checked with
+// VerifySignature.
+const SchnorrVerificationKeyType = "PadawanSchnorrVerificationKey2024"
+
+// padawanVocabulary is the IRI prefix of the terms defined by this package.
+const padawanVocabulary = "https://github.com/nicksrepo/padawanzero/ns#"
+
+var (
+	// didContexts defines every term of a DIDDocument that the DID core
+	// context does not, so JSON-LD processors keep them.
+	didContexts = []any{
+		"https://www.w3.org/ns/did/v1",
+		map[string]any{
+			"padawan":                  padawanVocabulary,
+			SchnorrVerificationKeyType: "padawan:" + SchnorrVerificationKeyType,
+			"publicKeyMultibase": map[string]string{
+				"@id":   "https://w3id.org/security#publicKeyMultibase",
+				"@type": "https://w3id.org/security#multibase",
+			},
+			"locationCommitment": "padawan:locationCommitment",
+			"zkpProof":           "padawan:zkpProof",
+		},
+	}
+
+	ErrInvalidDID  = errors.New("invalid padawan DID")
+	ErrDIDNotFound = errors.New("DID not found")
+)
+
+// multibaseBase64URL is the multibase prefix of unpadded base64url.
+const multibaseBase64URL = "u"
+
+// VerificationMethod is a DID document verification method. The public key is
+// the multibase encoded Edwards25519 point.
+type VerificationMethod struct {
+	ID                 string `json:"id"`
+	Type               string `json:"type"`
+	Controller         string `json:"controller"`
+	PublicKeyMultibase string `json:"publicKeyMultibase"`
+}
+
+// DIDDocument is a W3C DID document describing an address. The location
+// commitment and ZKP proof are carried as extension properties defined in
+// the document context.
+type DIDDocument struct {
+	Context            []any                `json:"@context"`
+	ID                 string               `json:"id"`
+	VerificationMethod []VerificationMethod `json:"verificationMethod"`
+	Authentication     []string             `json:"authentication"`
+	AssertionMethod    []string             `json:"assertionMethod"`
+	LocationCommitment string               `json:"locationCommitment"`
+	ZKPProof           string               `json:"zkpProof,omitempty"`
+}
+
+// DID returns the did:padawan identifier of the address.
+func (id AddressID) DID() string {
+	return DIDMethodPrefix + string(id)
+}
+
+// ParseDID extracts the AddressID from a did:padawan identifier, ignoring any fragment.
+func ParseDID(did string) (AddressID, error) {
+	did, _, _ = strings.Cut(did, "#")
+	id, ok := strings.CutPrefix(did, DIDMethodPrefix)
+	if !ok || id == "" {
+		return "", fmt.Errorf("%w: %s", ErrInvalidDID, did)
+	}
+	return AddressID(id), nil
+}
+
+// ToDIDDocument builds the DID document of ai. The hybrid public key is
+// published as a SchnorrVerificationKeyType method, used for both
+// authentication and assertions.
+func ToDIDDocument(ai *AddressInfo) (*DIDDocument, error) {
+	publicKey, err := base64.RawStdEncoding.DecodeString(ai.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key encoding: %w", err)
+	}
+	if len(publicKey) != 32 {
+		return nil, fmt.Errorf("invalid public key length %d", len(publicKey))
+	}
+
+	did := ai.ID().DID()
+	keyID := did + "#key-1"
+
+	return &DIDDocument{
+		Context: didContexts,
+		ID:      did,
+		VerificationMethod: []VerificationMethod{{
+			ID:                 keyID,
+			Type:               SchnorrVerificationKeyType,
+			Controller:         did,
+			PublicKeyMultibase: multibaseBase64URL + base64.RawURLEncoding.EncodeToString(publicKey),
+		}},
+		Authentication:     []string{keyID},
+		AssertionMethod:    []string{keyID},
+		LocationCommitment: ai.LocationCommitment,
+		ZKPProof:           ai.ZKPProof,
+	}, nil
+}
+
+// DIDResolver resolves did:padawan identifiers to DID documents.
+type DIDResolver interface {
+	Resolve(ctx context.Context, did string) (*DIDDocument, error)
+}
+
+// MemoryDIDResolver resolves DIDs of addresses added to it. It is safe for concurrent use.
+type MemoryDIDResolver struct {
+	mutex     sync.RWMutex
+	documents map[AddressID]*DIDDocument
+}
+
+// NewMemoryDIDResolver creates an empty MemoryDIDResolver.
+func NewMemoryDIDResolver() *MemoryDIDResolver {
+	return &MemoryDIDResolver{documents: make(map[AddressID]*DIDDocument)}
+}
+
+// Add makes the DID document of ai resolvable.
+func (r *MemoryDIDResolver) Add(ai *AddressInfo) error {
+	doc, err := ToDIDDocument(ai)
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.documents[ai.ID()] = doc
+	return nil
+}
+
+// Resolve returns the DID document for did.
+func (r *MemoryDIDResolver) Resolve(ctx context.Context, did string) (*DIDDocument, error) {
+	id, err := ParseDID(did)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	doc, ok := r.documents[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDIDNotFound, did)
+	}
+	return doc, nil
+}
@@ -0,0 +1,110 @@
+package account
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+	"go.dedis.ch/kyber/v3/sign/schnorr"
+)
+
+func TestToDIDDocument(t *testing.T) {
+	ai, err := GenerateAddress(40.7128, -74.0060, 256)
+	require.NoError(t, err)
+
+	doc, err := ToDIDDocument(ai)
+	require.NoError(t, err)
+
+	did := "did:padawan:" + string(ai.ID())
+	assert.Equal(t, did, doc.ID)
+	assert.Equal(t, "https://www.w3.org/ns/did/v1", doc.Context[0])
+	require.Len(t, doc.VerificationMethod, 1)
+
+	vm := doc.VerificationMethod[0]
+	assert.Equal(t, did+"#key-1", vm.ID)
+	assert.Equal(t, did, vm.Controller)
+	assert.Equal(t, []string{vm.ID}, doc.Authentication)
+	assert.Equal(t, []string{vm.ID}, doc.AssertionMethod)
+	assert.Equal(t, ai.LocationCommitment, doc.LocationCommitment)
+
+	// The method carries the same key bytes as the AddressInfo
+	assert.Equal(t, SchnorrVerificationKeyType, vm.Type)
+	encoded, ok := strings.CutPrefix(vm.PublicKeyMultibase, "u")
+	require.True(t, ok)
+	x, err := base64.RawURLEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	publicKey, err := base64.RawStdEncoding.DecodeString(ai.PublicKey)
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, x)
+
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Ed25519")
+
+	// Every non-core property is defined in the context
+	var decoded struct {
+		Context []any `json:"@context"`
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Len(t, decoded.Context, 2)
+	terms, ok := decoded.Context[1].(map[string]any)
+	require.True(t, ok)
+	for _, term := range []string{SchnorrVerificationKeyType, "publicKeyMultibase", "locationCommitment", "zkpProof"} {
+		assert.Contains(t, terms, term)
+	}
+
+	_, err = ToDIDDocument(&AddressInfo{PublicKey: "AAAA"})
+	assert.Error(t, err)
+}
+
+func TestParseDID(t *testing.T) {
+	id, err := ParseDID("did:padawan:abc123#key-1")
+	require.NoError(t, err)
+	assert.Equal(t, AddressID("abc123"), id)
+	assert.Equal(t, "did:padawan:abc123", id.DID())
+
+	_, err = ParseDID("did:key:abc123")
+	assert.ErrorIs(t, err, ErrInvalidDID)
+	_, err = ParseDID("did:padawan:")
+	assert.ErrorIs(t, err, ErrInvalidDID)
+}
+
+func TestMemoryDIDResolver(t *testing.T) {
+	ai, err := GenerateAddress(40.7128, -74.0060, 256)
+	require.NoError(t, err)
+
+	var resolver DIDResolver = NewMemoryDIDResolver()
+	require.NoError(t, resolver.(*MemoryDIDResolver).Add(ai))
+
+	doc, err := resolver.Resolve(context.Background(), ai.ID().DID()+"#key-1")
+	require.NoError(t, err)
+	assert.Equal(t, ai.ID().DID(), doc.ID)
+
+	_, err = resolver.Resolve(context.Background(), "did:padawan:unknown")
+	assert.ErrorIs(t, err, ErrDIDNotFound)
+}
+
+func TestDIDKeyVerifiesAddressSignatures(t *testing.T) {
+	na, err := NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+	ai, err := na.Info()
+	require.NoError(t, err)
+	doc, err := ToDIDDocument(ai)
+	require.NoError(t, err)
+
+	msg := []byte("hello padawan")
+	sig, err := na.Sign(msg)
+	require.NoError(t, err)
+
+	// A verifier holding only the DID document checks the signature with the
+	// padawan Schnorr scheme the method type names
+	publicKey, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(doc.VerificationMethod[0].PublicKeyMultibase, "u"))
+	require.NoError(t, err)
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	assert.NoError(t, schnorr.VerifyWithChecks(suite, publicKey, msg, sig))
+	assert.Error(t, schnorr.VerifyWithChecks(suite, publicKey, []byte("tampered"), sig))
+}
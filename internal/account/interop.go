@@ -0,0 +1,193 @@
+package account
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/zeebo/blake3"
+	"golang.org/x/crypto/sha3"
+	"golang.org/x/crypto/ssh"
+)
+
+// CompanionKind names an external ecosystem a companion identifier belongs to.
+type CompanionKind string
+
+const (
+	// CompanionEthereum is an EIP-55 checksummed Ethereum address backed by a secp256k1 key.
+	CompanionEthereum CompanionKind = "ethereum"
+	// CompanionSSH is an ssh-ed25519 public key in authorized_keys format.
+	CompanionSSH CompanionKind = "ssh-ed25519"
+)
+
+var ErrUnsupportedCompanion = errors.New("unsupported companion kind")
+
+// LinkageProof shows that an address and a companion identifier are controlled
+// by the same holder: both keys sign the same linkage statement.
+type LinkageProof struct {
+	Kind               CompanionKind `json:"kind"`
+	Identifier         string        `json:"identifier"`
+	AddressSignature   []byte        `json:"addressSignature"`
+	CompanionSignature []byte        `json:"companionSignature"`
+}
+
+// companionSeed derives the private key seed of a companion identifier from
+// the address signing key, so the identifier is reproducible from the keystore.
+func (na *NetworkAddress) companionSeed(kind CompanionKind) ([]byte, error) {
+	signingKey, err := na.SigningKey()
+	if err != nil {
+		return nil, err
+	}
+	material, err := signingKey.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	seed := make([]byte, 32)
+	blake3.DeriveKey("padawanzero companion "+string(kind)+" v1", material, seed)
+	return seed, nil
+}
+
+// CompanionIdentifier derives the identifier of kind controlled by the address.
+func (na *NetworkAddress) CompanionIdentifier(kind CompanionKind) (string, error) {
+	seed, err := na.companionSeed(kind)
+	if err != nil {
+		return "", err
+	}
+
+	switch kind {
+	case CompanionEthereum:
+		return ethereumAddress(secp256k1.PrivKeyFromBytes(seed).PubKey()), nil
+	case CompanionSSH:
+		return sshAuthorizedKey(ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey))
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedCompanion, kind)
+	}
+}
+
+// LinkCompanion derives the identifier of kind and proves it is linked to the address.
+func (na *NetworkAddress) LinkCompanion(kind CompanionKind) (*LinkageProof, error) {
+	ai, err := na.Info()
+	if err != nil {
+		return nil, err
+	}
+	identifier, err := na.CompanionIdentifier(kind)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := na.companionSeed(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := linkageStatement(ai.ID(), kind, identifier)
+
+	var companionSignature []byte
+	switch kind {
+	case CompanionEthereum:
+		// Ethereum tooling expects r || s || v
+		compact := ecdsa.SignCompact(secp256k1.PrivKeyFromBytes(seed), ethereumMessageHash(statement), false)
+		companionSignature = append(compact[1:], compact[0])
+	case CompanionSSH:
+		companionSignature = ed25519.Sign(ed25519.NewKeyFromSeed(seed), statement)
+	}
+
+	addressSignature, err := na.Sign(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LinkageProof{
+		Kind:               kind,
+		Identifier:         identifier,
+		AddressSignature:   addressSignature,
+		CompanionSignature: companionSignature,
+	}, nil
+}
+
+// VerifyLinkage checks that proof links the companion identifier to ai.
+func VerifyLinkage(ai *AddressInfo, proof *LinkageProof) error {
+	statement := linkageStatement(ai.ID(), proof.Kind, proof.Identifier)
+	if err := VerifySignature(ai, statement, proof.AddressSignature); err != nil {
+		return fmt.Errorf("invalid address signature: %w", err)
+	}
+
+	switch proof.Kind {
+	case CompanionEthereum:
+		sig := proof.CompanionSignature
+		if len(sig) != 65 {
+			return errors.New("invalid companion signature length")
+		}
+		compact := append([]byte{sig[64]}, sig[:64]...)
+		publicKey, _, err := ecdsa.RecoverCompact(compact, ethereumMessageHash(statement))
+		if err != nil {
+			return fmt.Errorf("invalid companion signature: %w", err)
+		}
+		if ethereumAddress(publicKey) != proof.Identifier {
+			return errors.New("companion signature does not match identifier")
+		}
+	case CompanionSSH:
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(proof.Identifier))
+		if err != nil {
+			return fmt.Errorf("invalid companion identifier: %w", err)
+		}
+		cryptoKey, ok := key.(ssh.CryptoPublicKey)
+		if !ok {
+			return errors.New("invalid companion identifier")
+		}
+		publicKey, ok := cryptoKey.CryptoPublicKey().(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(publicKey, statement, proof.CompanionSignature) {
+			return errors.New("invalid companion signature")
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedCompanion, proof.Kind)
+	}
+	return nil
+}
+
+func linkageStatement(id AddressID, kind CompanionKind, identifier string) []byte {
+	return []byte(fmt.Sprintf("padawan-link-v1\n%s\n%s:%s", id.DID(), kind, identifier))
+}
+
+// ethereumMessageHash hashes msg as an EIP-191 personal message.
+func ethereumMessageHash(msg []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	fmt.Fprintf(h, "\x19Ethereum Signed Message:\n%d", len(msg))
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+// ethereumAddress returns the EIP-55 checksummed address of publicKey.
+func ethereumAddress(publicKey *secp256k1.PublicKey) string {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(publicKey.SerializeUncompressed()[1:])
+	address := hex.EncodeToString(h.Sum(nil)[12:])
+
+	h = sha3.NewLegacyKeccak256()
+	h.Write([]byte(address))
+	checksum := hex.EncodeToString(h.Sum(nil))
+
+	var sb strings.Builder
+	sb.WriteString("0x")
+	for i, c := range address {
+		if c >= 'a' && checksum[i] >= '8' {
+			sb.WriteRune(c - 'a' + 'A')
+		} else {
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+// sshAuthorizedKey formats publicKey as an authorized_keys line.
+func sshAuthorizedKey(publicKey ed25519.PublicKey) (string, error) {
+	key, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))), nil
+}
@@ -0,0 +1,78 @@
+package account
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEthereumAddress(t *testing.T) {
+	// Private key 1 maps to a well-known address
+	key, err := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000001")
+	require.NoError(t, err)
+	assert.Equal(t, "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", ethereumAddress(secp256k1.PrivKeyFromBytes(key).PubKey()))
+}
+
+func TestCompanionIdentifier(t *testing.T) {
+	na, err := NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+
+	eth, err := na.CompanionIdentifier(CompanionEthereum)
+	require.NoError(t, err)
+	assert.Regexp(t, `^0x[0-9a-fA-F]{40}$`, eth)
+
+	// Derivation is deterministic
+	again, err := na.CompanionIdentifier(CompanionEthereum)
+	require.NoError(t, err)
+	assert.Equal(t, eth, again)
+
+	sshKey, err := na.CompanionIdentifier(CompanionSSH)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sshKey, "ssh-ed25519 "))
+
+	_, err = na.CompanionIdentifier("bitcoin")
+	assert.ErrorIs(t, err, ErrUnsupportedCompanion)
+}
+
+func TestLinkCompanion(t *testing.T) {
+	na, err := NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+	ai, err := na.Info()
+	require.NoError(t, err)
+
+	other, err := NewNetworkAddress(51.5074, -0.1278)
+	require.NoError(t, err)
+	otherInfo, err := other.Info()
+	require.NoError(t, err)
+
+	for _, kind := range []CompanionKind{CompanionEthereum, CompanionSSH} {
+		t.Run(string(kind), func(t *testing.T) {
+			proof, err := na.LinkCompanion(kind)
+			require.NoError(t, err)
+			assert.NoError(t, VerifyLinkage(ai, proof))
+
+			// The proof does not link the identifier to another address
+			assert.Error(t, VerifyLinkage(otherInfo, proof))
+
+			// A different identifier is rejected
+			otherProof, err := other.LinkCompanion(kind)
+			require.NoError(t, err)
+			forged := *proof
+			forged.Identifier = otherProof.Identifier
+			assert.Error(t, VerifyLinkage(ai, &forged))
+
+			// A tampered companion signature is rejected
+			tampered := *proof
+			tampered.CompanionSignature = append([]byte(nil), proof.CompanionSignature...)
+			tampered.CompanionSignature[10] ^= 0xff
+			assert.Error(t, VerifyLinkage(ai, &tampered))
+		})
+	}
+
+	_, err = na.LinkCompanion("bitcoin")
+	assert.ErrorIs(t, err, ErrUnsupportedCompanion)
+}
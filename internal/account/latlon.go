@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"math"
 
-	"github.com/nicksrepo/padawanzero/internal/common"
 	"go.dedis.ch/kyber/v3"
 	"go.dedis.ch/kyber/v3/group/edwards25519"
 	"go.dedis.ch/kyber/v3/util/random"
@@ -29,24 +28,32 @@ func ConvertToPrecisionGrid(lat, lon, precision float64) (SafeLatitudeLongitude,
 
 // CommitLocation function generates a cryptographic commitment to a location.
 func CommitLocation(classicalPrivateKey kyber.Scalar, location []byte) (kyber.Scalar, kyber.Point, error) {
-	suite := edwards25519.NewBlakeSHA256Ed25519()
-
-	// Generate a quantum key pair
-	quantumPublicKey, quantumPrivateKey, err := common.GenerateQuantumKeyPair()
+	combinedCommitment, _, err := commitLocation(classicalPrivateKey, location)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to generate quantum key pair: %v", err)
+		return nil, nil, err
 	}
+	return classicalPrivateKey, combinedCommitment, nil
+}
 
-	// Derive an Edwards25519 point from the quantum keys
-	commitment, err := common.QuantumDeriveEdwardsPoint(quantumPublicKey, quantumPrivateKey)
+// commitLocation creates a location commitment and returns the quantum keys
+// that, together with the classical private key, open it.
+func commitLocation(classicalPrivateKey kyber.Scalar, location []byte) (kyber.Point, *QuantumKeys, error) {
+	// Generate a quantum key pair and derive an Edwards25519 point from it
+	opening, commitment, err := newQuantumKeys()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to derive Edwards point: %v", err)
+		return nil, nil, err
 	}
 
-	// Combine the classical private key with the commitment
-	combinedCommitment := suite.Point().Mul(classicalPrivateKey, commitment)
+	return OpenLocationCommitment(classicalPrivateKey, commitment), opening, nil
+}
 
-	return classicalPrivateKey, combinedCommitment, nil
+// OpenLocationCommitment recomputes a location commitment from the classical
+// private key and the point recovered from its opening.
+func OpenLocationCommitment(classicalPrivateKey kyber.Scalar, openingPoint kyber.Point) kyber.Point {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+
+	// Combine the classical private key with the commitment
+	return suite.Point().Mul(classicalPrivateKey, openingPoint)
 }
 
 // Set updates the SafeLatitudeLongitude with new latitude and longitude values.
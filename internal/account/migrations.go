@@ -0,0 +1,7 @@
+package account
+
+import "github.com/nicksrepo/padawanzero/internal/migrate"
+
+// AccountStoreMigrations migrates persisted account storage. Append a
+// migrate.Step whenever its on-disk layout changes.
+var AccountStoreMigrations = migrate.MustNewRunner("accounts")
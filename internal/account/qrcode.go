@@ -0,0 +1,110 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// qrPayloadPrefix identifies QR payloads carrying an AddressInfo.
+const qrPayloadPrefix = "PZ:"
+
+// base45Alphabet is the RFC 9285 alphabet, which matches the QR code
+// alphanumeric mode and so packs denser than byte mode.
+const base45Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// QRPayload encodes the AddressInfo as compact QR text: the binary codec
+// output, base45 encoded behind a "PZ:" prefix.
+func (ai *AddressInfo) QRPayload() (string, error) {
+	data, err := ai.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return qrPayloadPrefix + encodeBase45(data), nil
+}
+
+// QRCode encodes the AddressInfo as a QR code with the given error correction
+// level. Use PNG, Image or ToSmallString on the result to render it.
+func (ai *AddressInfo) QRCode(level qrcode.RecoveryLevel) (*qrcode.QRCode, error) {
+	payload, err := ai.QRPayload()
+	if err != nil {
+		return nil, err
+	}
+	code, err := qrcode.New(payload, level)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding QR code: %w", err)
+	}
+	return code, nil
+}
+
+// DecodeQRPayload decodes the text scanned from a QR code produced by QRCode.
+func DecodeQRPayload(payload string) (*AddressInfo, error) {
+	if !strings.HasPrefix(payload, qrPayloadPrefix) {
+		return nil, errors.New("invalid QR payload prefix")
+	}
+
+	data, err := decodeBase45(payload[len(qrPayloadPrefix):])
+	if err != nil {
+		return nil, err
+	}
+
+	ai := &AddressInfo{}
+	if err := ai.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return ai, nil
+}
+
+// encodeBase45 encodes data as described in RFC 9285.
+func encodeBase45(data []byte) string {
+	var sb strings.Builder
+	sb.Grow((len(data)/2)*3 + 2)
+	for i := 0; i+1 < len(data); i += 2 {
+		n := int(data[i])*256 + int(data[i+1])
+		sb.WriteByte(base45Alphabet[n%45])
+		sb.WriteByte(base45Alphabet[(n/45)%45])
+		sb.WriteByte(base45Alphabet[n/(45*45)])
+	}
+	if len(data)%2 == 1 {
+		n := int(data[len(data)-1])
+		sb.WriteByte(base45Alphabet[n%45])
+		sb.WriteByte(base45Alphabet[n/45])
+	}
+	return sb.String()
+}
+
+// decodeBase45 decodes a string produced by encodeBase45.
+func decodeBase45(s string) ([]byte, error) {
+	if len(s)%3 == 1 {
+		return nil, errors.New("invalid base45 length")
+	}
+
+	values := make([]int, len(s))
+	for i := 0; i < len(s); i++ {
+		v := strings.IndexByte(base45Alphabet, s[i])
+		if v < 0 {
+			return nil, fmt.Errorf("invalid base45 character %q", s[i])
+		}
+		values[i] = v
+	}
+
+	out := make([]byte, 0, len(s)/3*2+1)
+	for i := 0; i < len(values); i += 3 {
+		if len(values)-i == 2 {
+			n := values[i] + values[i+1]*45
+			if n > 0xff {
+				return nil, errors.New("invalid base45 encoding")
+			}
+			out = append(out, byte(n))
+			break
+		}
+		n := values[i] + values[i+1]*45 + values[i+2]*45*45
+		if n > 0xffff {
+			return nil, errors.New("invalid base45 encoding")
+		}
+		out = append(out, byte(n>>8), byte(n))
+	}
+	return out, nil
+}
@@ -0,0 +1,71 @@
+package account
+
+import (
+	"testing"
+
+	"github.com/skip2/go-qrcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBase45(t *testing.T) {
+	// Test vectors from RFC 9285
+	tests := []struct {
+		decoded string
+		encoded string
+	}{
+		{"AB", "BB8"},
+		{"Hello!!", "%69 VD92EX0"},
+		{"base-45", "UJCLQE7W581"},
+		{"ietf!", "QED8WEX0"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.encoded, encodeBase45([]byte(tt.decoded)))
+		decoded, err := decodeBase45(tt.encoded)
+		require.NoError(t, err)
+		assert.Equal(t, tt.decoded, string(decoded))
+	}
+
+	for _, invalid := range []string{"GGW", "A", "ab1", "ZZ"} {
+		_, err := decodeBase45(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestAddressInfoQRPayload(t *testing.T) {
+	ai, err := GenerateAddress(40.7128, -74.0060, 256)
+	require.NoError(t, err)
+
+	payload, err := ai.QRPayload()
+	require.NoError(t, err)
+	assert.Regexp(t, `^PZ:[0-9A-Z $%*+\-./:]+$`, payload)
+
+	decoded, err := DecodeQRPayload(payload)
+	require.NoError(t, err)
+	assert.Equal(t, ai, decoded)
+
+	_, err = DecodeQRPayload("XX:" + payload[len(qrPayloadPrefix):])
+	assert.Error(t, err)
+
+	_, err = DecodeQRPayload(qrPayloadPrefix + "lowercase")
+	assert.Error(t, err)
+}
+
+func TestAddressInfoQRCode(t *testing.T) {
+	ai, err := GenerateAddress(40.7128, -74.0060, 256)
+	require.NoError(t, err)
+
+	low, err := ai.QRCode(qrcode.Low)
+	require.NoError(t, err)
+	high, err := ai.QRCode(qrcode.High)
+	require.NoError(t, err)
+
+	// Higher error correction needs a larger symbol for the same payload
+	assert.Greater(t, high.VersionNumber, low.VersionNumber)
+
+	png, err := low.PNG(256)
+	require.NoError(t, err)
+	assert.NotEmpty(t, png)
+}
@@ -0,0 +1,71 @@
+package account
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+	"go.dedis.ch/kyber/v3/sign/schnorr"
+)
+
+// SigningKey returns the private key matching the combined public key: the
+// classical private key plus the scalar recovered from the quantum keys.
+func (na *NetworkAddress) SigningKey() (kyber.Scalar, error) {
+	if na.PrivateKey == nil || na.QuantumKeys == nil {
+		return nil, errors.New("address is missing key material")
+	}
+
+	quantumScalar, err := na.QuantumKeys.Scalar()
+	if err != nil {
+		return nil, fmt.Errorf("error recovering quantum scalar: %w", err)
+	}
+
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	return suite.Scalar().Add(na.PrivateKey, quantumScalar), nil
+}
+
+// Sign produces a Schnorr signature over msg that verifies against the address public key.
+func (na *NetworkAddress) Sign(msg []byte) ([]byte, error) {
+	signingKey, err := na.SigningKey()
+	if err != nil {
+		return nil, err
+	}
+	return schnorr.Sign(edwards25519.NewBlakeSHA256Ed25519(), signingKey, msg)
+}
+
+// Info returns the serializable AddressInfo of the NetworkAddress. The ZKP
+// proof is only set once GenerateZKP has been called.
+func (na *NetworkAddress) Info() (*AddressInfo, error) {
+	publicKeyBytes, err := na.PublicKey.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	locationCommitmentBytes, err := na.LocationCommitment.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	ai := &AddressInfo{
+		PublicKey:          base64.RawStdEncoding.EncodeToString(publicKeyBytes),
+		LocationCommitment: base64.RawStdEncoding.EncodeToString(locationCommitmentBytes),
+	}
+	if na.zkpProof != nil {
+		ai.ZKPProof = na.zkpProof.String()
+	}
+	if na.Nonce != nil {
+		ai.NonceValue = base64.StdEncoding.EncodeToString(na.Nonce.Value)
+		ai.NonceHash = base64.StdEncoding.EncodeToString(na.Nonce.Hash)
+	}
+	return ai, nil
+}
+
+// VerifySignature checks a signature produced by Sign against the public key of ai.
+func VerifySignature(ai *AddressInfo, msg, sig []byte) error {
+	publicKey, err := base64.RawStdEncoding.DecodeString(ai.PublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key encoding: %w", err)
+	}
+	return schnorr.VerifyWithChecks(edwards25519.NewBlakeSHA256Ed25519(), publicKey, msg, sig)
+}
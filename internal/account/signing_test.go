@@ -0,0 +1,44 @@
+package account
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigningKeyMatchesPublicKey(t *testing.T) {
+	na, err := NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+
+	signingKey, err := na.SigningKey()
+	require.NoError(t, err)
+	assert.True(t, na.PublicKey.Equal(na.Suite.Point().Mul(signingKey, nil)))
+
+	_, err = (&NetworkAddress{}).SigningKey()
+	assert.Error(t, err)
+}
+
+func TestSignVerify(t *testing.T) {
+	na, err := NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+	require.NoError(t, na.GenerateZKP(256))
+
+	ai, err := na.Info()
+	require.NoError(t, err)
+	assert.NotEmpty(t, ai.ZKPProof)
+	assert.NotEmpty(t, ai.NonceValue)
+
+	msg := []byte("hello padawan")
+	sig, err := na.Sign(msg)
+	require.NoError(t, err)
+
+	assert.NoError(t, VerifySignature(ai, msg, sig))
+	assert.Error(t, VerifySignature(ai, []byte("tampered"), sig))
+
+	other, err := NewNetworkAddress(51.5074, -0.1278)
+	require.NoError(t, err)
+	otherInfo, err := other.Info()
+	require.NoError(t, err)
+	assert.Error(t, VerifySignature(otherInfo, msg, sig))
+}
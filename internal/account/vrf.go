@@ -0,0 +1,149 @@
+package account
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/zeebo/blake3"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+)
+
+// The VRF follows the ECVRF construction of RFC 9381 over Edwards25519, with
+// the group operations and hashing of the rest of the package: the input is
+// hashed to a point H, Gamma = x·H, and a Chaum-Pedersen proof shows that Gamma
+// and the public key share the discrete log x. The output is derived from the
+// cofactor-cleared Gamma, so it is unique for an address and input.
+
+const (
+	// VRFOutputSize is the length of a VRF output.
+	VRFOutputSize = 32
+	// VRFProofSize is the length of a VRF proof: Gamma | c | s.
+	VRFProofSize = 3 * 32
+
+	vrfDomain = "padawanzero vrf v1"
+)
+
+var ErrInvalidVRFProof = errors.New("invalid VRF proof")
+
+// VRF evaluates the verifiable random function of the address on input,
+// returning the pseudorandom output and a proof anyone holding the address
+// public key can check with VerifyVRF.
+func (na *NetworkAddress) VRF(input []byte) (output, proof []byte, err error) {
+	x, err := na.SigningKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	publicKey, err := na.PublicKey.MarshalBinary()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	h := vrfHashToPoint(suite, publicKey, input)
+	gamma := suite.Point().Mul(x, h)
+
+	// Deterministic nonce, as in RFC 8032
+	secret, err := x.MarshalBinary()
+	if err != nil {
+		return nil, nil, err
+	}
+	k := vrfHashToScalar(suite, secret, pointBytes(h))
+	c := vrfChallenge(suite, publicKey, h, gamma, suite.Point().Mul(k, nil), suite.Point().Mul(k, h))
+	s := suite.Scalar().Add(k, suite.Scalar().Mul(c, x))
+
+	proof = make([]byte, 0, VRFProofSize)
+	proof = append(proof, pointBytes(gamma)...)
+	proof = append(proof, scalarBytes(c)...)
+	proof = append(proof, scalarBytes(s)...)
+	return vrfOutput(suite, gamma), proof, nil
+}
+
+// VerifyVRF checks a proof produced by VRF for input against the public key of
+// ai and returns the VRF output.
+func VerifyVRF(ai *AddressInfo, input, proof []byte) ([]byte, error) {
+	publicKey, err := base64.RawStdEncoding.DecodeString(ai.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key encoding: %w", err)
+	}
+	if len(proof) != VRFProofSize {
+		return nil, fmt.Errorf("%w: invalid length %d", ErrInvalidVRFProof, len(proof))
+	}
+
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	y := suite.Point()
+	if err := y.UnmarshalBinary(publicKey); err != nil || isSmallOrder(suite, y) {
+		return nil, errors.New("invalid public key")
+	}
+	gamma := suite.Point()
+	if err := gamma.UnmarshalBinary(proof[:32]); err != nil || isSmallOrder(suite, gamma) {
+		return nil, fmt.Errorf("%w: invalid gamma", ErrInvalidVRFProof)
+	}
+	c := suite.Scalar()
+	if err := c.UnmarshalBinary(proof[32:64]); err != nil {
+		return nil, fmt.Errorf("%w: invalid challenge", ErrInvalidVRFProof)
+	}
+	s := suite.Scalar()
+	if err := s.UnmarshalBinary(proof[64:]); err != nil {
+		return nil, fmt.Errorf("%w: invalid response", ErrInvalidVRFProof)
+	}
+
+	// U = s·G - c·Y, V = s·H - c·Gamma
+	h := vrfHashToPoint(suite, publicKey, input)
+	u := suite.Point().Sub(suite.Point().Mul(s, nil), suite.Point().Mul(c, y))
+	v := suite.Point().Sub(suite.Point().Mul(s, h), suite.Point().Mul(c, gamma))
+
+	if !c.Equal(vrfChallenge(suite, publicKey, h, gamma, u, v)) {
+		return nil, ErrInvalidVRFProof
+	}
+	return vrfOutput(suite, gamma), nil
+}
+
+// vrfHashToPoint maps the public key and input to a point of the prime-order
+// subgroup whose discrete log is unknown.
+func vrfHashToPoint(suite *edwards25519.SuiteEd25519, publicKey, input []byte) kyber.Point {
+	return suite.Point().Pick(suite.XOF(vrfTranscript("point", publicKey, input)))
+}
+
+func vrfHashToScalar(suite *edwards25519.SuiteEd25519, parts ...[]byte) kyber.Scalar {
+	return suite.Scalar().Pick(suite.XOF(vrfTranscript("scalar", parts...)))
+}
+
+func vrfChallenge(suite *edwards25519.SuiteEd25519, publicKey []byte, h, gamma, u, v kyber.Point) kyber.Scalar {
+	return vrfHashToScalar(suite, publicKey, pointBytes(h), pointBytes(gamma), pointBytes(u), pointBytes(v))
+}
+
+func vrfOutput(suite *edwards25519.SuiteEd25519, gamma kyber.Point) []byte {
+	cleared := suite.Point().Mul(suite.Scalar().SetInt64(8), gamma)
+	output := blake3.Sum256(vrfTranscript("output", pointBytes(cleared)))
+	return output[:]
+}
+
+// vrfTranscript length-prefixes each part under a domain and label so
+// distinct hash inputs cannot collide.
+func vrfTranscript(label string, parts ...[]byte) []byte {
+	transcript := append([]byte(vrfDomain+" "+label), 0)
+	for _, part := range parts {
+		transcript = binary.AppendUvarint(transcript, uint64(len(part)))
+		transcript = append(transcript, part...)
+	}
+	return transcript
+}
+
+func isSmallOrder(suite *edwards25519.SuiteEd25519, p kyber.Point) bool {
+	return suite.Point().Mul(suite.Scalar().SetInt64(8), p).Equal(suite.Point().Null())
+}
+
+// pointBytes and scalarBytes encode group elements; Edwards25519 marshaling
+// does not fail.
+func pointBytes(p kyber.Point) []byte {
+	b, _ := p.MarshalBinary()
+	return b
+}
+
+func scalarBytes(s kyber.Scalar) []byte {
+	b, _ := s.MarshalBinary()
+	return b
+}
@@ -0,0 +1,54 @@
+package account
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVRF(t *testing.T) {
+	na, err := NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+	ai, err := na.Info()
+	require.NoError(t, err)
+
+	input := []byte("epoch 42")
+	output, proof, err := na.VRF(input)
+	require.NoError(t, err)
+	assert.Len(t, output, VRFOutputSize)
+	assert.Len(t, proof, VRFProofSize)
+
+	verified, err := VerifyVRF(ai, input, proof)
+	require.NoError(t, err)
+	assert.Equal(t, output, verified)
+
+	// The output is deterministic and depends on the input
+	again, _, err := na.VRF(input)
+	require.NoError(t, err)
+	assert.Equal(t, output, again)
+	other, _, err := na.VRF([]byte("epoch 43"))
+	require.NoError(t, err)
+	assert.NotEqual(t, output, other)
+
+	_, err = VerifyVRF(ai, []byte("epoch 43"), proof)
+	assert.ErrorIs(t, err, ErrInvalidVRFProof)
+
+	// The proof does not verify for another address
+	otherAddress, err := NewNetworkAddress(51.5074, -0.1278)
+	require.NoError(t, err)
+	otherInfo, err := otherAddress.Info()
+	require.NoError(t, err)
+	_, err = VerifyVRF(otherInfo, input, proof)
+	assert.ErrorIs(t, err, ErrInvalidVRFProof)
+
+	for _, i := range []int{0, 40, 80} {
+		tampered := append([]byte(nil), proof...)
+		tampered[i] ^= 0x01
+		_, err = VerifyVRF(ai, input, tampered)
+		assert.ErrorIs(t, err, ErrInvalidVRFProof)
+	}
+
+	_, err = VerifyVRF(ai, input, proof[:64])
+	assert.ErrorIs(t, err, ErrInvalidVRFProof)
+}
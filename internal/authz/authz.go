@@ -0,0 +1,257 @@
+// Package authz authenticates requests made on behalf of an address.
+//
+// A request carries the caller's AddressInfo, a challenge nonce previously
+// issued for its AddressID, a timestamp and a signature over SigningMessage,
+// which covers every field of the AddressInfo. Authenticator checks the
+// address structure and ZKP with the verifier package, that the request
+// timestamp is fresh and the signature, consumes the nonce so the request
+// cannot be replayed, and attaches the verified AddressID to the request
+// context. The ZKP is bound to the address rather than to the request, so
+// freshness is established by the signed timestamp and nonce.
+//
+// Middleware serves REST handlers and UnaryServerInterceptor and
+// StreamServerInterceptor serve gRPC servers. Other transports can call
+// AuthenticateRequest with their own metadata lookup.
+package authz
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nicksrepo/padawanzero/internal/account"
+	"github.com/nicksrepo/padawanzero/internal/state"
+	"github.com/nicksrepo/padawanzero/verifier"
+)
+
+// Metadata keys carrying the credentials. They are used as HTTP headers and,
+// lowercased, as gRPC metadata keys.
+const (
+	AddressKey   = "X-Padawan-Address"
+	NonceKey     = "X-Padawan-Nonce"
+	TimestampKey = "X-Padawan-Timestamp"
+	SignatureKey = "X-Padawan-Signature"
+)
+
+// DefaultLifetime is how long a signed request stays fresh.
+const DefaultLifetime = 5 * time.Minute
+
+var (
+	ErrMissingCredentials = errors.New("missing credentials")
+	ErrInvalidAddress     = errors.New("invalid address")
+	ErrStaleRequest       = errors.New("request timestamp is not fresh")
+	ErrInvalidSignature   = errors.New("invalid signature")
+	ErrInvalidNonce       = errors.New("invalid or already used nonce")
+)
+
+// NonceManager consumes the single-use nonces issued to addresses.
+type NonceManager interface {
+	ConsumeNonce(address string, value []byte) bool
+}
+
+// stateNonceManager consumes nonces from the state package nonce store.
+type stateNonceManager struct{}
+
+func (stateNonceManager) ConsumeNonce(address string, value []byte) bool {
+	return state.ConsumeNonce(address, value)
+}
+
+// Credentials are the authentication values extracted from a request.
+type Credentials struct {
+	Address   *account.AddressInfo
+	Nonce     []byte
+	Timestamp int64
+	Signature []byte
+}
+
+// Config configures an Authenticator. Zero values select the defaults.
+type Config struct {
+	// Nonces consumes request nonces. Defaults to the state nonce store.
+	Nonces NonceManager
+	// Lifetime bounds the age of a request timestamp. Defaults to DefaultLifetime.
+	Lifetime time.Duration
+	// Verifier checks the address structure. Defaults to verifier.New().
+	Verifier *verifier.Verifier
+}
+
+// Authenticator verifies request credentials.
+type Authenticator struct {
+	nonces   NonceManager
+	lifetime time.Duration
+	verifier *verifier.Verifier
+}
+
+// New creates an Authenticator from config.
+func New(config Config) *Authenticator {
+	a := &Authenticator{
+		nonces:   config.Nonces,
+		lifetime: config.Lifetime,
+		verifier: config.Verifier,
+	}
+	if a.nonces == nil {
+		a.nonces = stateNonceManager{}
+	}
+	if a.lifetime <= 0 {
+		a.lifetime = DefaultLifetime
+	}
+	if a.verifier == nil {
+		a.verifier = verifier.New()
+	}
+	return a
+}
+
+// SigningMessage builds the message a client signs for a request to resource,
+// e.g. "GET /accounts" for HTTP or the full method name for gRPC. It covers
+// the binary encoding of address, so the location commitment, ZKP and nonce
+// fields cannot be swapped without invalidating the signature.
+func SigningMessage(address *account.AddressInfo, nonce []byte, timestamp int64, resource string) ([]byte, error) {
+	encoded, err := address.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("padawan-authz-v2\n%s\n%s\n%d\n%s",
+		base64.StdEncoding.EncodeToString(encoded), base64.StdEncoding.EncodeToString(nonce), timestamp, resource)), nil
+}
+
+// NewCredentials signs a request to resource on behalf of na, using a nonce
+// issued for its AddressID. The address must already have a ZKP.
+func NewCredentials(na *account.NetworkAddress, nonce []byte, timestamp int64, resource string) (*Credentials, error) {
+	ai, err := na.Info()
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := SigningMessage(ai, nonce, timestamp, resource)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := na.Sign(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credentials{
+		Address:   ai,
+		Nonce:     nonce,
+		Timestamp: timestamp,
+		Signature: signature,
+	}, nil
+}
+
+// Encode writes the credentials using set, such as http.Header.Set.
+func (c *Credentials) Encode(set func(key, value string)) error {
+	address, err := c.Address.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	set(AddressKey, base64.StdEncoding.EncodeToString(address))
+	set(NonceKey, base64.StdEncoding.EncodeToString(c.Nonce))
+	set(TimestampKey, strconv.FormatInt(c.Timestamp, 10))
+	set(SignatureKey, base64.StdEncoding.EncodeToString(c.Signature))
+	return nil
+}
+
+// ExtractCredentials reads credentials using get to look up metadata keys,
+// such as http.Header.Get or a gRPC metadata lookup.
+func ExtractCredentials(get func(key string) string) (*Credentials, error) {
+	address, nonce, timestamp, signature := get(AddressKey), get(NonceKey), get(TimestampKey), get(SignatureKey)
+	if address == "" || nonce == "" || timestamp == "" || signature == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	addressBytes, err := base64.StdEncoding.DecodeString(address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+	ai := &account.AddressInfo{}
+	if err := ai.UnmarshalBinary(addressBytes); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+
+	creds := &Credentials{Address: ai}
+	if creds.Nonce, err = base64.StdEncoding.DecodeString(nonce); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNonce, err)
+	}
+	if creds.Timestamp, err = strconv.ParseInt(timestamp, 10, 64); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStaleRequest, err)
+	}
+	if creds.Signature, err = base64.StdEncoding.DecodeString(signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return creds, nil
+}
+
+// Authenticate verifies creds for a request to resource and returns ctx with
+// the verified AddressID attached. The nonce is consumed last, so rejected
+// requests do not burn it.
+func (a *Authenticator) Authenticate(ctx context.Context, creds *Credentials, resource string) (context.Context, error) {
+	if creds == nil || creds.Address == nil {
+		return nil, ErrMissingCredentials
+	}
+
+	if err := a.verifier.Verify((*verifier.Address)(creds.Address)).Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+
+	if !state.IsFresh(creds.Timestamp, a.lifetime) {
+		return nil, ErrStaleRequest
+	}
+
+	id := creds.Address.ID()
+	msg, err := SigningMessage(creds.Address, creds.Nonce, creds.Timestamp, resource)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+	if err := account.VerifySignature(creds.Address, msg, creds.Signature); err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	if !a.nonces.ConsumeNonce(string(id), creds.Nonce) {
+		return nil, ErrInvalidNonce
+	}
+
+	return WithAddressID(ctx, id), nil
+}
+
+// AuthenticateRequest extracts credentials using get and authenticates them
+// for resource. It is the transport-neutral core of Middleware and the gRPC
+// interceptors.
+func (a *Authenticator) AuthenticateRequest(ctx context.Context, get func(key string) string, resource string) (context.Context, error) {
+	creds, err := ExtractCredentials(get)
+	if err != nil {
+		return nil, err
+	}
+	return a.Authenticate(ctx, creds, resource)
+}
+
+// Middleware authenticates HTTP requests before passing them to next. The
+// signed resource is the request method and path, e.g. "GET /accounts".
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := a.AuthenticateRequest(r.Context(), r.Header.Get, r.Method+" "+r.URL.Path)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+type contextKey struct{}
+
+// WithAddressID returns a copy of ctx carrying id.
+func WithAddressID(ctx context.Context, id account.AddressID) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// AddressIDFromContext returns the verified AddressID attached by Authenticate.
+func AddressIDFromContext(ctx context.Context) (account.AddressID, bool) {
+	id, ok := ctx.Value(contextKey{}).(account.AddressID)
+	return id, ok
+}
@@ -0,0 +1,166 @@
+package authz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nicksrepo/padawanzero/internal/account"
+	"github.com/nicksrepo/padawanzero/internal/common"
+	"github.com/nicksrepo/padawanzero/internal/state"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAddress(t *testing.T) (*account.NetworkAddress, account.AddressID) {
+	na, err := account.NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+	require.NoError(t, na.GenerateZKP(256))
+
+	ai, err := na.Info()
+	require.NoError(t, err)
+	return na, ai.ID()
+}
+
+func newSignedRequest(t *testing.T, na *account.NetworkAddress, nonce []byte, timestamp int64) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/accounts", nil)
+	creds, err := NewCredentials(na, nonce, timestamp, "GET /accounts")
+	require.NoError(t, err)
+	require.NoError(t, creds.Encode(req.Header.Set))
+	return req
+}
+
+func TestMiddleware(t *testing.T) {
+	na, id := newTestAddress(t)
+	nonce := state.GenerateOrUpdateNonce(string(id))
+
+	var gotID account.AddressID
+	handler := New(Config{}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID, _ = AddressIDFromContext(r.Context())
+	}))
+
+	req := newSignedRequest(t, na, nonce.Value, time.Now().Unix())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, id, gotID)
+
+	// Replaying the same request fails because the nonce was consumed
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestMiddlewareRejects(t *testing.T) {
+	na, id := newTestAddress(t)
+	handler := New(Config{}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not be called")
+	}))
+
+	// Missing credentials
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/accounts", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Signed for a different resource
+	nonce := state.GenerateOrUpdateNonce(string(id))
+	req := newSignedRequest(t, na, nonce.Value, time.Now().Unix())
+	req.URL.Path = "/admin"
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Nonce was not issued for this address
+	req = newSignedRequest(t, na, make([]byte, 32), time.Now().Unix())
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// The rejected requests did not burn the issued nonce
+	assert.True(t, state.ValidateNonce(string(id), *nonce))
+}
+
+func TestAuthenticate(t *testing.T) {
+	na, id := newTestAddress(t)
+	now := time.Unix(1700000000, 0)
+	state.SetClock(common.NewManualClock(now))
+	defer state.SetClock(common.SystemClock)
+
+	nonce := state.GenerateOrUpdateNonce(string(id))
+	a := New(Config{Lifetime: time.Minute})
+
+	stale, err := NewCredentials(na, nonce.Value, now.Add(-2*time.Minute).Unix(), "rpc")
+	require.NoError(t, err)
+	_, err = a.Authenticate(context.Background(), stale, "rpc")
+	assert.ErrorIs(t, err, ErrStaleRequest)
+
+	creds, err := NewCredentials(na, nonce.Value, now.Unix(), "rpc")
+	require.NoError(t, err)
+
+	tampered := *creds
+	tampered.Signature = append([]byte(nil), creds.Signature...)
+	tampered.Signature[0] ^= 0xff
+	_, err = a.Authenticate(context.Background(), &tampered, "rpc")
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	invalid := *creds
+	invalid.Address = &account.AddressInfo{}
+	_, err = a.Authenticate(context.Background(), &invalid, "rpc")
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+
+	// A ZKP that does not verify
+	forged := *creds.Address
+	forged.ZKPProof = "deadbeef|cafebabe"
+	invalid.Address = &forged
+	_, err = a.Authenticate(context.Background(), &invalid, "rpc")
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+
+	// Every AddressInfo field is signed, so valid values from another
+	// address cannot be swapped in
+	other, err := account.NewNetworkAddress(51.5074, -0.1278)
+	require.NoError(t, err)
+	require.NoError(t, other.GenerateZKP(256))
+	otherInfo, err := other.Info()
+	require.NoError(t, err)
+	for name, swap := range map[string]func(ai *account.AddressInfo){
+		"location commitment": func(ai *account.AddressInfo) { ai.LocationCommitment = otherInfo.LocationCommitment },
+		"nonce":               func(ai *account.AddressInfo) { ai.NonceValue, ai.NonceHash = otherInfo.NonceValue, otherInfo.NonceHash },
+	} {
+		swapped := *creds.Address
+		swap(&swapped)
+		invalid.Address = &swapped
+		_, err = a.Authenticate(context.Background(), &invalid, "rpc")
+		assert.ErrorIs(t, err, ErrInvalidSignature, name)
+	}
+
+	ctx, err := a.Authenticate(context.Background(), creds, "rpc")
+	require.NoError(t, err)
+	gotID, ok := AddressIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, id, gotID)
+
+	_, err = a.Authenticate(context.Background(), creds, "rpc")
+	assert.ErrorIs(t, err, ErrInvalidNonce)
+
+	_, err = a.Authenticate(context.Background(), nil, "rpc")
+	assert.ErrorIs(t, err, ErrMissingCredentials)
+	_, err = a.Authenticate(context.Background(), &Credentials{}, "rpc")
+	assert.ErrorIs(t, err, ErrMissingCredentials)
+}
+
+func TestExtractCredentials(t *testing.T) {
+	_, err := ExtractCredentials(func(string) string { return "" })
+	assert.ErrorIs(t, err, ErrMissingCredentials)
+
+	md := map[string]string{
+		AddressKey:   "!!",
+		NonceKey:     "AAAA",
+		TimestampKey: "1",
+		SignatureKey: "AAAA",
+	}
+	_, err = ExtractCredentials(func(key string) string { return md[key] })
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+}
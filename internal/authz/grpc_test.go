@@ -0,0 +1,86 @@
+package authz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nicksrepo/padawanzero/internal/account"
+	"github.com/nicksrepo/padawanzero/internal/state"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testMethod = "/padawan.Accounts/Get"
+
+func newIncomingContext(t *testing.T, na *account.NetworkAddress, nonce []byte) context.Context {
+	creds, err := NewCredentials(na, nonce, time.Now().Unix(), testMethod)
+	require.NoError(t, err)
+
+	md := metadata.MD{}
+	require.NoError(t, creds.Encode(func(key, value string) { md.Set(key, value) }))
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	na, id := newTestAddress(t)
+	nonce := state.GenerateOrUpdateNonce(string(id))
+	interceptor := New(Config{}).UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: testMethod}
+
+	var gotID account.AddressID
+	handler := func(ctx context.Context, req any) (any, error) {
+		gotID, _ = AddressIDFromContext(ctx)
+		return "ok", nil
+	}
+
+	ctx := newIncomingContext(t, na, nonce.Value)
+	resp, err := interceptor(ctx, nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+	assert.Equal(t, id, gotID)
+
+	// Replaying the call fails because the nonce was consumed
+	_, err = interceptor(ctx, nil, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = interceptor(context.Background(), nil, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	na, id := newTestAddress(t)
+	nonce := state.GenerateOrUpdateNonce(string(id))
+	interceptor := New(Config{}).StreamServerInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: testMethod}
+
+	var gotID account.AddressID
+	handler := func(srv any, ss grpc.ServerStream) error {
+		gotID, _ = AddressIDFromContext(ss.Context())
+		return nil
+	}
+
+	stream := &testServerStream{ctx: newIncomingContext(t, na, nonce.Value)}
+	require.NoError(t, interceptor(nil, stream, info, handler))
+	assert.Equal(t, id, gotID)
+
+	// Signed for a different method
+	nonce = state.GenerateOrUpdateNonce(string(id))
+	stream = &testServerStream{ctx: newIncomingContext(t, na, nonce.Value)}
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/padawan.Accounts/Delete"}, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
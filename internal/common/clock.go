@@ -0,0 +1,52 @@
+package common
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a source of the current time. Time-dependent logic reads the time
+// through a Clock so it can be tested deterministically.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock is the Clock backed by the system wall clock.
+var SystemClock Clock = systemClock{}
+
+// ManualClock is a Clock that only moves when told to. It is safe for concurrent use.
+type ManualClock struct {
+	mutex sync.RWMutex
+	now   time.Time
+}
+
+// NewManualClock creates a ManualClock set to now.
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.now
+}
+
+// Set moves the clock to now.
+func (c *ManualClock) Set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = now
+}
+
+// Advance moves the clock forward by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -1,3 +1,5 @@
+//go:build cgo && liboqs
+
 #include <oqs/oqs.h>
 #include <string.h>
 
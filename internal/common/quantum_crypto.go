@@ -1,20 +1,16 @@
 package common
 
-/*
-#cgo CFLAGS: -I/usr/local/include
-#cgo LDFLAGS: -L/usr/local/lib -loqs
-#include <stdlib.h>
-#include "quantum_crypto.h"
-*/
-import "C"
 import (
 	"crypto/sha256"
 	"fmt"
+
 	"go.dedis.ch/kyber/v3"
 	"go.dedis.ch/kyber/v3/group/edwards25519"
-	"unsafe"
 )
 
+// Kyber512 sizes shared by every KEM backend. The liboqs backend is only
+// compiled with cgo and the liboqs build tag; all other builds (including
+// CGO_ENABLED=0 and js/wasm) use the pure-Go implementation.
 const (
 	PublicKeySize    = 800
 	SecretKeySize    = 1632
@@ -22,52 +18,6 @@ const (
 	SharedSecretSize = 32
 )
 
-func GenerateQuantumKeyPair() ([]byte, []byte, error) {
-	publicKey := make([]byte, PublicKeySize)
-	secretKey := make([]byte, SecretKeySize)
-
-	result := C.generate_keypair((*C.uint8_t)(unsafe.Pointer(&publicKey[0])), (*C.uint8_t)(unsafe.Pointer(&secretKey[0])))
-	if result == 0 {
-		return nil, nil, fmt.Errorf("failed to generate key pair")
-	}
-
-	return publicKey, secretKey, nil
-}
-
-func Encapsulate(publicKey []byte) ([]byte, []byte, error) {
-	if len(publicKey) != PublicKeySize {
-		return nil, nil, fmt.Errorf("invalid public key size")
-	}
-
-	ciphertext := make([]byte, CiphertextSize)
-	sharedSecret := make([]byte, SharedSecretSize)
-
-	result := C.encapsulate((*C.uint8_t)(unsafe.Pointer(&publicKey[0])), (*C.uint8_t)(unsafe.Pointer(&ciphertext[0])), (*C.uint8_t)(unsafe.Pointer(&sharedSecret[0])))
-	if result == 0 {
-		return nil, nil, fmt.Errorf("encapsulation failed")
-	}
-
-	return ciphertext, sharedSecret, nil
-}
-
-func Decapsulate(secretKey, ciphertext []byte) ([]byte, error) {
-	if len(secretKey) != SecretKeySize {
-		return nil, fmt.Errorf("invalid secret key size")
-	}
-	if len(ciphertext) != CiphertextSize {
-		return nil, fmt.Errorf("invalid ciphertext size")
-	}
-
-	sharedSecret := make([]byte, SharedSecretSize)
-
-	result := C.decapsulate((*C.uint8_t)(unsafe.Pointer(&secretKey[0])), (*C.uint8_t)(unsafe.Pointer(&ciphertext[0])), (*C.uint8_t)(unsafe.Pointer(&sharedSecret[0])))
-	if result == 0 {
-		return nil, fmt.Errorf("decapsulation failed")
-	}
-
-	return sharedSecret, nil
-}
-
 func QuantumPointMul(point, scalar []byte) ([]byte, error) {
 	if len(point) != PublicKeySize || len(scalar) != SecretKeySize {
 		return nil, fmt.Errorf("invalid input lengths")
@@ -93,25 +43,91 @@ func QuantumPointMul(point, scalar []byte) ([]byte, error) {
 }
 
 func QuantumDeriveEdwardsPoint(quantumPublicKey, quantumPrivateKey []byte) (kyber.Point, error) {
+	point, _, err := QuantumEncapsulateEdwardsPoint(quantumPublicKey, quantumPrivateKey)
+	return point, err
+}
+
+// QuantumEncapsulateEdwardsPoint derives an Edwards25519 point like
+// QuantumDeriveEdwardsPoint and also returns the ciphertext of the shared
+// secret, so the point can later be recovered with QuantumRecoverEdwardsPoint.
+func QuantumEncapsulateEdwardsPoint(quantumPublicKey, quantumPrivateKey []byte) (kyber.Point, []byte, error) {
 	if len(quantumPublicKey) != PublicKeySize || len(quantumPrivateKey) != SecretKeySize {
-		return nil, fmt.Errorf("invalid input lengths")
+		return nil, nil, fmt.Errorf("invalid input lengths")
 	}
 
 	// Use encapsulation to generate a shared secret
-	_, sharedSecret, err := Encapsulate(quantumPublicKey)
+	ciphertext, sharedSecret, err := Encapsulate(quantumPublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return deriveEdwardsPoint(sharedSecret, quantumPrivateKey), ciphertext, nil
+}
+
+// QuantumRecoverEdwardsPoint re-derives the point returned by
+// QuantumEncapsulateEdwardsPoint from the private key and ciphertext.
+func QuantumRecoverEdwardsPoint(quantumPrivateKey, ciphertext []byte) (kyber.Point, error) {
+	sharedSecret, err := Decapsulate(quantumPrivateKey, ciphertext)
 	if err != nil {
 		return nil, err
 	}
 
+	return deriveEdwardsPoint(sharedSecret, quantumPrivateKey), nil
+}
+
+// QuantumRecoverEdwardsScalar recovers the discrete logarithm of the point
+// returned by QuantumEncapsulateEdwardsPoint, so it can be used as a signing key.
+func QuantumRecoverEdwardsScalar(quantumPrivateKey, ciphertext []byte) (kyber.Scalar, error) {
+	sharedSecret, err := Decapsulate(quantumPrivateKey, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	return deriveEdwardsScalar(sharedSecret, quantumPrivateKey), nil
+}
+
+// deriveEdwardsPoint derives the point as a scalar multiple of the base point,
+// so its discrete logarithm is known and the combined public key of an address
+// has a matching signing key. Earlier builds picked the point directly from
+// the seed, which yields a different point with no usable discrete logarithm;
+// keystore blobs from those builds carry version 1 and are rejected.
+func deriveEdwardsPoint(sharedSecret, quantumPrivateKey []byte) kyber.Point {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	return suite.Point().Mul(deriveEdwardsScalar(sharedSecret, quantumPrivateKey), nil)
+}
+
+func deriveEdwardsScalar(sharedSecret, quantumPrivateKey []byte) kyber.Scalar {
 	// Combine shared secret with private key to derive a new seed
 	h := sha256.New()
 	h.Write(sharedSecret)
 	h.Write(quantumPrivateKey)
 	seed := h.Sum(nil)
 
-	// Use the seed to generate an Edwards25519 point
+	// Use the seed to generate an Edwards25519 scalar
 	suite := edwards25519.NewBlakeSHA256Ed25519()
-	point := suite.Point().Pick(suite.XOF(seed))
+	return suite.Scalar().Pick(suite.XOF(seed))
+}
+
+// SelfTest runs a key generation and encapsulation round trip through the KEM
+// backend and the Edwards point derivation, returning an error if the backend
+// is unusable.
+func SelfTest() error {
+	publicKey, secretKey, err := GenerateQuantumKeyPair()
+	if err != nil {
+		return fmt.Errorf("self-test: %w", err)
+	}
 
-	return point, nil
+	point, ciphertext, err := QuantumEncapsulateEdwardsPoint(publicKey, secretKey)
+	if err != nil {
+		return fmt.Errorf("self-test: %w", err)
+	}
+
+	recovered, err := QuantumRecoverEdwardsPoint(secretKey, ciphertext)
+	if err != nil {
+		return fmt.Errorf("self-test: %w", err)
+	}
+	if !point.Equal(recovered) {
+		return fmt.Errorf("self-test: decapsulated shared secret does not match")
+	}
+	return nil
 }
@@ -0,0 +1,61 @@
+//go:build cgo && liboqs
+
+package common
+
+/*
+#cgo CFLAGS: -I/usr/local/include
+#cgo LDFLAGS: -L/usr/local/lib -loqs
+#include <stdlib.h>
+#include "quantum_crypto.h"
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+func GenerateQuantumKeyPair() ([]byte, []byte, error) {
+	publicKey := make([]byte, PublicKeySize)
+	secretKey := make([]byte, SecretKeySize)
+
+	result := C.generate_keypair((*C.uint8_t)(unsafe.Pointer(&publicKey[0])), (*C.uint8_t)(unsafe.Pointer(&secretKey[0])))
+	if result == 0 {
+		return nil, nil, fmt.Errorf("failed to generate key pair")
+	}
+
+	return publicKey, secretKey, nil
+}
+
+func Encapsulate(publicKey []byte) ([]byte, []byte, error) {
+	if len(publicKey) != PublicKeySize {
+		return nil, nil, fmt.Errorf("invalid public key size")
+	}
+
+	ciphertext := make([]byte, CiphertextSize)
+	sharedSecret := make([]byte, SharedSecretSize)
+
+	result := C.encapsulate((*C.uint8_t)(unsafe.Pointer(&publicKey[0])), (*C.uint8_t)(unsafe.Pointer(&ciphertext[0])), (*C.uint8_t)(unsafe.Pointer(&sharedSecret[0])))
+	if result == 0 {
+		return nil, nil, fmt.Errorf("encapsulation failed")
+	}
+
+	return ciphertext, sharedSecret, nil
+}
+
+func Decapsulate(secretKey, ciphertext []byte) ([]byte, error) {
+	if len(secretKey) != SecretKeySize {
+		return nil, fmt.Errorf("invalid secret key size")
+	}
+	if len(ciphertext) != CiphertextSize {
+		return nil, fmt.Errorf("invalid ciphertext size")
+	}
+
+	sharedSecret := make([]byte, SharedSecretSize)
+
+	result := C.decapsulate((*C.uint8_t)(unsafe.Pointer(&secretKey[0])), (*C.uint8_t)(unsafe.Pointer(&ciphertext[0])), (*C.uint8_t)(unsafe.Pointer(&sharedSecret[0])))
+	if result == 0 {
+		return nil, fmt.Errorf("decapsulation failed")
+	}
+
+	return sharedSecret, nil
+}
@@ -0,0 +1,56 @@
+//go:build !cgo || !liboqs
+
+package common
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/cloudflare/circl/kem/kyber/kyber512"
+)
+
+func GenerateQuantumKeyPair() ([]byte, []byte, error) {
+	pk, sk, err := kyber512.GenerateKeyPair(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key pair: %w", err)
+	}
+
+	publicKey := make([]byte, PublicKeySize)
+	secretKey := make([]byte, SecretKeySize)
+	pk.Pack(publicKey)
+	sk.Pack(secretKey)
+
+	return publicKey, secretKey, nil
+}
+
+func Encapsulate(publicKey []byte) ([]byte, []byte, error) {
+	if len(publicKey) != PublicKeySize {
+		return nil, nil, fmt.Errorf("invalid public key size")
+	}
+
+	var pk kyber512.PublicKey
+	pk.Unpack(publicKey)
+
+	ciphertext := make([]byte, CiphertextSize)
+	sharedSecret := make([]byte, SharedSecretSize)
+	pk.EncapsulateTo(ciphertext, sharedSecret, nil)
+
+	return ciphertext, sharedSecret, nil
+}
+
+func Decapsulate(secretKey, ciphertext []byte) ([]byte, error) {
+	if len(secretKey) != SecretKeySize {
+		return nil, fmt.Errorf("invalid secret key size")
+	}
+	if len(ciphertext) != CiphertextSize {
+		return nil, fmt.Errorf("invalid ciphertext size")
+	}
+
+	var sk kyber512.PrivateKey
+	sk.Unpack(secretKey)
+
+	sharedSecret := make([]byte, SharedSecretSize)
+	sk.DecapsulateTo(sharedSecret, ciphertext)
+
+	return sharedSecret, nil
+}
@@ -0,0 +1,65 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+)
+
+func TestEncapsulateDecapsulate(t *testing.T) {
+	publicKey, secretKey, err := GenerateQuantumKeyPair()
+	require.NoError(t, err)
+	assert.Len(t, publicKey, PublicKeySize)
+	assert.Len(t, secretKey, SecretKeySize)
+
+	ciphertext, sharedSecret, err := Encapsulate(publicKey)
+	require.NoError(t, err)
+	assert.Len(t, ciphertext, CiphertextSize)
+	assert.Len(t, sharedSecret, SharedSecretSize)
+
+	decapsulated, err := Decapsulate(secretKey, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, sharedSecret, decapsulated)
+
+	// Invalid input sizes
+	_, _, err = Encapsulate(publicKey[:10])
+	assert.Error(t, err)
+	_, err = Decapsulate(secretKey[:10], ciphertext)
+	assert.Error(t, err)
+	_, err = Decapsulate(secretKey, ciphertext[:10])
+	assert.Error(t, err)
+}
+
+func TestQuantumDeriveEdwardsPoint(t *testing.T) {
+	publicKey, secretKey, err := GenerateQuantumKeyPair()
+	require.NoError(t, err)
+
+	point, err := QuantumDeriveEdwardsPoint(publicKey, secretKey)
+	require.NoError(t, err)
+	assert.NotNil(t, point)
+
+	_, err = QuantumDeriveEdwardsPoint(publicKey[:10], secretKey)
+	assert.Error(t, err)
+}
+
+func TestQuantumRecoverEdwardsPoint(t *testing.T) {
+	publicKey, secretKey, err := GenerateQuantumKeyPair()
+	require.NoError(t, err)
+
+	point, ciphertext, err := QuantumEncapsulateEdwardsPoint(publicKey, secretKey)
+	require.NoError(t, err)
+
+	recovered, err := QuantumRecoverEdwardsPoint(secretKey, ciphertext)
+	require.NoError(t, err)
+	assert.True(t, point.Equal(recovered))
+
+	scalar, err := QuantumRecoverEdwardsScalar(secretKey, ciphertext)
+	require.NoError(t, err)
+	assert.True(t, point.Equal(edwards25519.NewBlakeSHA256Ed25519().Point().Mul(scalar, nil)))
+}
+
+func TestSelfTest(t *testing.T) {
+	assert.NoError(t, SelfTest())
+}
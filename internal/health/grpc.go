@@ -0,0 +1,86 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultWatchInterval is how often a gRPC health watch re-runs the checks.
+const DefaultWatchInterval = 5 * time.Second
+
+// Service names understood by the gRPC health service. The empty name is the
+// overall server status and maps to readiness.
+const (
+	LivenessService  = "liveness"
+	ReadinessService = "readiness"
+)
+
+// GRPCServer implements the standard grpc.health.v1.Health service on top of
+// a Registry. Register it with healthpb.RegisterHealthServer.
+type GRPCServer struct {
+	healthpb.UnimplementedHealthServer
+
+	registry *Registry
+	interval time.Duration
+}
+
+// GRPCServer returns a gRPC health service reporting the checks of r.
+func (r *Registry) GRPCServer() *GRPCServer {
+	return &GRPCServer{registry: r, interval: DefaultWatchInterval}
+}
+
+func (s *GRPCServer) status(ctx context.Context, service string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	var kind Kind
+	switch service {
+	case "", ReadinessService:
+		kind = Readiness
+	case LivenessService:
+		kind = Liveness
+	default:
+		return healthpb.HealthCheckResponse_SERVICE_UNKNOWN, status.Errorf(codes.NotFound, "unknown service %q", service)
+	}
+
+	if s.registry.Check(ctx, kind).Healthy {
+		return healthpb.HealthCheckResponse_SERVING, nil
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING, nil
+}
+
+// Check runs the checks for the requested service.
+func (s *GRPCServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	serving, err := s.status(ctx, req.GetService())
+	if err != nil {
+		return nil, err
+	}
+	return &healthpb.HealthCheckResponse{Status: serving}, nil
+}
+
+// Watch sends the status of the requested service, then re-runs the checks
+// every interval and sends the status again whenever it changes. Unknown
+// services are reported as SERVICE_UNKNOWN, as the protocol requires.
+func (s *GRPCServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ctx := stream.Context()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		serving, _ := s.status(ctx, req.GetService())
+		if serving != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: serving}); err != nil {
+				return err
+			}
+			last = serving
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCServerCheck(t *testing.T) {
+	r := NewRegistry()
+	r.Register("live", Liveness, func(context.Context) error { return nil })
+	r.Register("storage", Readiness, func(context.Context) error { return errors.New("unreachable") })
+	s := r.GRPCServer()
+
+	for service, want := range map[string]healthpb.HealthCheckResponse_ServingStatus{
+		"":               healthpb.HealthCheckResponse_NOT_SERVING,
+		ReadinessService: healthpb.HealthCheckResponse_NOT_SERVING,
+		LivenessService:  healthpb.HealthCheckResponse_SERVING,
+	} {
+		resp, err := s.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		assert.Equal(t, want, resp.Status, "service %q", service)
+	}
+
+	_, err := s.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "consensus"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+type testWatchServer struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent chan *healthpb.HealthCheckResponse
+}
+
+func (s *testWatchServer) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testWatchServer) Send(resp *healthpb.HealthCheckResponse) error {
+	s.sent <- resp
+	return nil
+}
+
+func TestGRPCServerWatch(t *testing.T) {
+	var healthy atomic.Bool
+	r := NewRegistry()
+	r.Register("storage", Readiness, func(context.Context) error {
+		if !healthy.Load() {
+			return errors.New("unreachable")
+		}
+		return nil
+	})
+	s := r.GRPCServer()
+	s.interval = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &testWatchServer{ctx: ctx, sent: make(chan *healthpb.HealthCheckResponse, 1)}
+	done := make(chan error)
+	go func() { done <- s.Watch(&healthpb.HealthCheckRequest{}, stream) }()
+
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, (<-stream.sent).Status)
+
+	// Only changes are sent
+	healthy.Store(true)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, (<-stream.sent).Status)
+
+	cancel()
+	assert.Equal(t, codes.Canceled, status.Code(<-done))
+}
@@ -0,0 +1,193 @@
+// Package health reports per-component status for liveness and readiness
+// probes. Components register a check with a Registry, which serves the
+// results on /healthz and /readyz and through the standard gRPC health
+// service.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nicksrepo/padawanzero/internal/common"
+	"github.com/nicksrepo/padawanzero/internal/state"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// DefaultTimeout bounds how long a probe waits for its checks. A check still
+// running at the deadline is reported as timed out, even if it ignores its
+// context.
+const DefaultTimeout = 2 * time.Second
+
+// Kind selects which probes a check contributes to.
+type Kind int
+
+const (
+	// Liveness checks fail when the process must be restarted. They also gate readiness.
+	Liveness Kind = iota
+	// Readiness checks fail while the process should not receive traffic.
+	Readiness
+)
+
+// CheckFunc returns nil when the component is healthy.
+type CheckFunc func(ctx context.Context) error
+
+// ComponentStatus is the outcome of one component check.
+type ComponentStatus struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report is the outcome of a probe.
+type Report struct {
+	Healthy    bool              `json:"healthy"`
+	Components []ComponentStatus `json:"components"`
+}
+
+type component struct {
+	name  string
+	kind  Kind
+	check CheckFunc
+}
+
+// Registry holds the component checks of a process.
+type Registry struct {
+	mutex      sync.RWMutex
+	components []component
+	timeout    time.Duration
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{timeout: DefaultTimeout}
+}
+
+// NewDefaultRegistry creates a Registry with the built-in component checks:
+// the crypto backend self-test and the nonce pruner.
+func NewDefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.Register("crypto", Liveness, CryptoSelfTest())
+	r.Register("nonce-pruner", Readiness, NoncePruner)
+	return r
+}
+
+// Register adds a component check. Registering a name again replaces its check.
+func (r *Registry) Register(name string, kind Kind, check CheckFunc) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, c := range r.components {
+		if c.name == name {
+			r.components[i] = component{name: name, kind: kind, check: check}
+			return
+		}
+	}
+	r.components = append(r.components, component{name: name, kind: kind, check: check})
+}
+
+// Check runs the checks for the given probe kind concurrently. A readiness
+// probe also runs the liveness checks.
+func (r *Registry) Check(ctx context.Context, kind Kind) Report {
+	r.mutex.RLock()
+	var components []component
+	for _, c := range r.components {
+		if c.kind == Liveness || kind == Readiness {
+			components = append(components, c)
+		}
+	}
+	r.mutex.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// Buffered so checks that outlive the probe can still finish and exit
+	results := make([]chan error, len(components))
+	for i, c := range components {
+		results[i] = make(chan error, 1)
+		go func(c component, result chan<- error) {
+			result <- c.check(ctx)
+		}(c, results[i])
+	}
+
+	report := Report{Healthy: true, Components: make([]ComponentStatus, len(components))}
+	for i, c := range components {
+		var err error
+		select {
+		case err = <-results[i]:
+		case <-ctx.Done():
+			select {
+			case err = <-results[i]:
+			default:
+				err = fmt.Errorf("check timed out: %w", ctx.Err())
+			}
+		}
+
+		status := ComponentStatus{Name: c.name, Healthy: true}
+		if err != nil {
+			status.Healthy = false
+			status.Error = err.Error()
+		}
+		report.Components[i] = status
+	}
+
+	for _, status := range report.Components {
+		report.Healthy = report.Healthy && status.Healthy
+	}
+	return report
+}
+
+// Handler serves the report for the given probe kind, with status 200 when
+// healthy and 503 otherwise.
+func (r *Registry) Handler(kind Kind) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Check(req.Context(), kind)
+
+		w.Header().Set("Content-Type", "application/json")
+		if report.Healthy {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+}
+
+// RegisterHandlers serves the liveness probe on /healthz and the readiness
+// probe on /readyz.
+func (r *Registry) RegisterHandlers(mux *http.ServeMux) {
+	mux.Handle("/healthz", r.Handler(Liveness))
+	mux.Handle("/readyz", r.Handler(Readiness))
+}
+
+// ServingStatus reports whether the process should receive traffic.
+func (r *Registry) ServingStatus(ctx context.Context) bool {
+	return r.Check(ctx, Readiness).Healthy
+}
+
+// CryptoSelfTest returns a check that runs the crypto backend self-test once
+// and reports its cached result.
+func CryptoSelfTest() CheckFunc {
+	var once sync.Once
+	var err error
+	return func(context.Context) error {
+		once.Do(func() {
+			err = common.SelfTest()
+		})
+		return err
+	}
+}
+
+// NoncePruner checks that the nonce pruner is running.
+func NoncePruner(context.Context) error {
+	if !state.NoncePrunerRunning() {
+		return errors.New("nonce pruner is not running")
+	}
+	return nil
+}
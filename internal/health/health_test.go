@@ -0,0 +1,109 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nicksrepo/padawanzero/internal/state"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryCheck(t *testing.T) {
+	r := NewRegistry()
+	r.Register("live", Liveness, func(context.Context) error { return nil })
+	r.Register("storage", Readiness, func(context.Context) error { return errors.New("unreachable") })
+
+	live := r.Check(context.Background(), Liveness)
+	assert.True(t, live.Healthy)
+	assert.Len(t, live.Components, 1)
+
+	ready := r.Check(context.Background(), Readiness)
+	assert.False(t, ready.Healthy)
+	require.Len(t, ready.Components, 2)
+	assert.Equal(t, ComponentStatus{Name: "storage", Healthy: false, Error: "unreachable"}, ready.Components[1])
+	assert.False(t, r.ServingStatus(context.Background()))
+
+	// Re-registering replaces the check
+	r.Register("storage", Readiness, func(context.Context) error { return nil })
+	assert.True(t, r.ServingStatus(context.Background()))
+}
+
+func TestRegistryTimeout(t *testing.T) {
+	r := NewRegistry()
+	r.timeout = 10 * time.Millisecond
+	r.Register("consensus", Readiness, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := r.Check(context.Background(), Readiness)
+	assert.False(t, report.Healthy)
+	assert.Contains(t, report.Components[0].Error, "deadline exceeded")
+}
+
+func TestRegistryTimeoutIgnoredContext(t *testing.T) {
+	r := NewRegistry()
+	r.timeout = 10 * time.Millisecond
+
+	// A blocked check that never looks at its context
+	release := make(chan struct{})
+	defer close(release)
+	r.Register("storage", Readiness, func(context.Context) error {
+		<-release
+		return nil
+	})
+	r.Register("live", Liveness, func(context.Context) error { return nil })
+
+	done := make(chan Report)
+	go func() { done <- r.Check(context.Background(), Readiness) }()
+
+	select {
+	case report := <-done:
+		assert.False(t, report.Healthy)
+		assert.Contains(t, report.Components[0].Error, "timed out")
+		assert.True(t, report.Components[1].Healthy)
+	case <-time.After(time.Second):
+		t.Fatal("Check did not return after its timeout")
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	mux := http.NewServeMux()
+	NewDefaultRegistry().RegisterHandlers(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var report Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.True(t, report.Healthy)
+	assert.Equal(t, "crypto", report.Components[0].Name)
+
+	// Not ready until the nonce pruner runs
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		state.RunNoncePruner(ctx, time.Hour)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	require.Eventually(t, state.NoncePrunerRunning, time.Second, time.Millisecond)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
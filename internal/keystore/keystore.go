@@ -0,0 +1,207 @@
+// Package keystore exports and imports passphrase-protected NetworkAddress
+// identities so they can be migrated between devices.
+//
+// An exported blob is laid out as
+//
+//	magic(4) | version(1) | argon2 time(4) | argon2 memory(4) | argon2 threads(1) | salt(16) | nonce(24) | ciphertext
+//
+// The key is derived from the passphrase with argon2id and the payload is
+// sealed with XChaCha20-Poly1305, using the header as additional data.
+//
+// Split and Combine distribute the same key material as t-of-n threshold
+// shares instead of a single passphrase-protected blob.
+package keystore
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/nicksrepo/padawanzero/internal/account"
+
+	jsoniter "github.com/json-iterator/go"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+const (
+	// Version is the current blob format version. Version 1 blobs were
+	// written before the quantum-derived point became a multiple of the base
+	// point. The same key material now imports to a different public key, so
+	// they are rejected. No version 1 blobs were released.
+	Version = 2
+
+	saltSize   = 16
+	headerSize = 4 + 1 + 4 + 4 + 1 + saltSize + chacha20poly1305.NonceSizeX
+
+	argonTime    = 3
+	argonMemory  = 64 * 1024 // KiB
+	argonThreads = 4
+	argonKeySize = chacha20poly1305.KeySize
+
+	// Upper bounds on the argon2 parameters accepted from an imported header.
+	// The KDF runs before the tag is checked, so these cap the work a crafted
+	// blob can cause.
+	maxArgonTime    = 4 * argonTime
+	maxArgonMemory  = 4 * argonMemory // KiB
+	maxArgonThreads = 4 * argonThreads
+)
+
+var magic = []byte("PZKS")
+
+var (
+	ErrEmptyPassphrase    = errors.New("passphrase must not be empty")
+	ErrInvalidFormat      = errors.New("invalid keystore format")
+	ErrUnsupportedVersion = errors.New("unsupported keystore version")
+	ErrDecryptionFailed   = errors.New("decryption failed: wrong passphrase or corrupted data")
+)
+
+// payload is the plaintext sealed inside an exported blob.
+type payload struct {
+	AnonGeoLocation   []int               `json:"anonGeoLocation"`
+	PrivateKey        []byte              `json:"privateKey"`
+	QuantumKeys       account.QuantumKeys `json:"quantumKeys"`
+	CommitmentOpening account.QuantumKeys `json:"commitmentOpening"`
+}
+
+// Export encrypts the key material of address under passphrase.
+func Export(address *account.NetworkAddress, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+	plaintext, err := marshalPayload(address)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, headerSize)
+	header = append(header, magic...)
+	header = append(header, Version)
+	header = binary.BigEndian.AppendUint32(header, argonTime)
+	header = binary.BigEndian.AppendUint32(header, argonMemory)
+	header = append(header, argonThreads)
+
+	saltAndNonce := make([]byte, saltSize+chacha20poly1305.NonceSizeX)
+	if _, err := rand.Read(saltAndNonce); err != nil {
+		return nil, fmt.Errorf("error generating salt: %w", err)
+	}
+	header = append(header, saltAndNonce...)
+
+	aead, err := newAEAD(passphrase, header)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := header[headerSize-chacha20poly1305.NonceSizeX:]
+	return aead.Seal(header, nonce, plaintext, header), nil
+}
+
+// Import decrypts a blob produced by Export and reconstructs the NetworkAddress.
+// The returned address has no nonce or ZKP; callers regenerate those as needed.
+func Import(data []byte, passphrase string) (*account.NetworkAddress, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+	if len(data) < headerSize || !bytes.Equal(data[:len(magic)], magic) {
+		return nil, ErrInvalidFormat
+	}
+	if data[len(magic)] != Version {
+		return nil, ErrUnsupportedVersion
+	}
+
+	header := data[:headerSize]
+	aead, err := newAEAD(passphrase, header)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := header[headerSize-chacha20poly1305.NonceSizeX:]
+	plaintext, err := aead.Open(nil, nonce, data[headerSize:], header)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+
+	return unmarshalPayload(plaintext)
+}
+
+// marshalPayload serializes the key material of address.
+func marshalPayload(address *account.NetworkAddress) ([]byte, error) {
+	if address == nil || address.PrivateKey == nil || address.QuantumKeys == nil || address.CommitmentOpening == nil {
+		return nil, errors.New("address is missing key material")
+	}
+
+	privateKey, err := address.PrivateKey.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling private key: %w", err)
+	}
+
+	plaintext, err := json.Marshal(&payload{
+		AnonGeoLocation:   address.AnonGeoLocation,
+		PrivateKey:        privateKey,
+		QuantumKeys:       *address.QuantumKeys,
+		CommitmentOpening: *address.CommitmentOpening,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling payload: %w", err)
+	}
+	return plaintext, nil
+}
+
+// unmarshalPayload reconstructs a NetworkAddress from serialized key material.
+func unmarshalPayload(plaintext []byte) (*account.NetworkAddress, error) {
+	var p payload
+	if err := json.Unmarshal(plaintext, &p); err != nil {
+		return nil, fmt.Errorf("error unmarshaling payload: %w", err)
+	}
+
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	privateKey := suite.Scalar()
+	if err := privateKey.UnmarshalBinary(p.PrivateKey); err != nil {
+		return nil, fmt.Errorf("error unmarshaling private key: %w", err)
+	}
+
+	quantumPoint, err := p.QuantumKeys.Point()
+	if err != nil {
+		return nil, fmt.Errorf("error recovering quantum keys: %w", err)
+	}
+
+	openingPoint, err := p.CommitmentOpening.Point()
+	if err != nil {
+		return nil, fmt.Errorf("error recovering commitment opening: %w", err)
+	}
+
+	return &account.NetworkAddress{
+		AnonGeoLocation:    p.AnonGeoLocation,
+		LocationCommitment: account.OpenLocationCommitment(privateKey, openingPoint),
+		PrivateKey:         privateKey,
+		PublicKey:          account.CombinePublicKey(suite, privateKey, quantumPoint),
+		Suite:              suite,
+		QuantumKeys:        &p.QuantumKeys,
+		CommitmentOpening:  &p.CommitmentOpening,
+	}, nil
+}
+
+// newAEAD derives the sealing key from passphrase using the KDF parameters
+// and salt stored in header.
+func newAEAD(passphrase string, header []byte) (cipher.AEAD, error) {
+	offset := len(magic) + 1
+	iterations := binary.BigEndian.Uint32(header[offset:])
+	memory := binary.BigEndian.Uint32(header[offset+4:])
+	threads := header[offset+8]
+	salt := header[offset+9 : offset+9+saltSize]
+
+	if iterations == 0 || iterations > maxArgonTime ||
+		memory == 0 || memory > maxArgonMemory ||
+		threads == 0 || threads > maxArgonThreads {
+		return nil, ErrInvalidFormat
+	}
+
+	key := argon2.IDKey([]byte(passphrase), salt, iterations, memory, threads, argonKeySize)
+	return chacha20poly1305.NewX(key)
+}
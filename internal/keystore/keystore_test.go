@@ -0,0 +1,93 @@
+package keystore
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/nicksrepo/padawanzero/internal/account"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImport(t *testing.T) {
+	na, err := account.NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+
+	data, err := Export(na, "correct horse battery staple")
+	require.NoError(t, err)
+
+	imported, err := Import(data, "correct horse battery staple")
+	require.NoError(t, err)
+	assert.Equal(t, na.AnonGeoLocation, imported.AnonGeoLocation)
+	assert.True(t, na.PrivateKey.Equal(imported.PrivateKey))
+	assert.True(t, na.PublicKey.Equal(imported.PublicKey))
+	assert.True(t, na.LocationCommitment.Equal(imported.LocationCommitment))
+	assert.Equal(t, na.QuantumKeys, imported.QuantumKeys)
+	assert.Equal(t, na.CommitmentOpening, imported.CommitmentOpening)
+}
+
+func TestImportErrors(t *testing.T) {
+	na, err := account.NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+
+	data, err := Export(na, "passphrase")
+	require.NoError(t, err)
+
+	_, err = Import(data, "wrong passphrase")
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	_, err = Import(data, "")
+	assert.ErrorIs(t, err, ErrEmptyPassphrase)
+
+	_, err = Import(data[:10], "passphrase")
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+
+	// Tampering with the header invalidates the authentication tag
+	tampered := append([]byte(nil), data...)
+	tampered[headerSize-1] ^= 0xff
+	_, err = Import(tampered, "passphrase")
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	unsupported := append([]byte(nil), data...)
+	for _, version := range []byte{1, Version + 1} {
+		unsupported[len(magic)] = version
+		_, err = Import(unsupported, "passphrase")
+		assert.ErrorIs(t, err, ErrUnsupportedVersion, "version %d", version)
+	}
+}
+
+func TestImportRejectsExcessiveKDFParameters(t *testing.T) {
+	na, err := account.NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+
+	data, err := Export(na, "passphrase")
+	require.NoError(t, err)
+
+	offset := len(magic) + 1
+	for name, tamper := range map[string]func([]byte){
+		"time":    func(b []byte) { binary.BigEndian.PutUint32(b[offset:], 0xffffffff) },
+		"memory":  func(b []byte) { binary.BigEndian.PutUint32(b[offset+4:], maxArgonMemory+1) },
+		"threads": func(b []byte) { b[offset+8] = 0xff },
+	} {
+		t.Run(name, func(t *testing.T) {
+			tampered := append([]byte(nil), data...)
+			tamper(tampered)
+
+			// Rejected before running the KDF, so this returns immediately
+			_, err := Import(tampered, "passphrase")
+			assert.ErrorIs(t, err, ErrInvalidFormat)
+		})
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	na, err := account.NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+
+	_, err = Export(na, "")
+	assert.ErrorIs(t, err, ErrEmptyPassphrase)
+
+	_, err = Export(&account.NetworkAddress{}, "passphrase")
+	assert.Error(t, err)
+}
@@ -0,0 +1,279 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"github.com/nicksrepo/padawanzero/internal/account"
+
+	"github.com/zeebo/blake3"
+)
+
+// Threshold shares split the key material of an address (the classical scalar,
+// the Kyber keys and the commitment opening) so that any threshold of them
+// reconstruct it while fewer reveal nothing. Sharing is Shamir's scheme over
+// GF(2^8), applied bytewise to the same payload Export seals.
+//
+// A serialized share is laid out as
+//
+//	magic(4) | version(1) | threshold(1) | x(1) | set id(16) | digest(32) | data
+//
+// The set id identifies the split (and refresh) a share belongs to, and the
+// digest is the blake3 hash of the shared payload, used to check reconstruction.
+
+const (
+	// ShareVersion is the current share format version.
+	ShareVersion = 1
+
+	// MaxShares is the largest number of shares a payload can be split into.
+	MaxShares = 255
+
+	setIDSize       = 16
+	digestSize      = 32
+	shareHeaderSize = 4 + 1 + 1 + 1 + setIDSize + digestSize
+)
+
+var shareMagic = []byte("PZSH")
+
+var (
+	ErrInvalidThreshold   = errors.New("invalid threshold")
+	ErrInsufficientShares = errors.New("insufficient shares")
+	ErrShareMismatch      = errors.New("shares belong to different sets")
+	ErrInvalidShare       = errors.New("invalid share")
+)
+
+// Share is one threshold share of the key material of an address.
+type Share struct {
+	SetID     [setIDSize]byte
+	Threshold byte
+	X         byte
+	Digest    [digestSize]byte
+	Data      []byte
+}
+
+// MarshalBinary encodes the share.
+func (s *Share) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, shareHeaderSize+len(s.Data))
+	data = append(data, shareMagic...)
+	data = append(data, ShareVersion, s.Threshold, s.X)
+	data = append(data, s.SetID[:]...)
+	data = append(data, s.Digest[:]...)
+	return append(data, s.Data...), nil
+}
+
+// UnmarshalBinary decodes a share encoded with MarshalBinary.
+func (s *Share) UnmarshalBinary(data []byte) error {
+	if len(data) <= shareHeaderSize || !bytes.Equal(data[:4], shareMagic) {
+		return ErrInvalidFormat
+	}
+	if data[4] != ShareVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, data[4])
+	}
+	if data[5] == 0 || data[6] == 0 {
+		return ErrInvalidShare
+	}
+
+	s.Threshold = data[5]
+	s.X = data[6]
+	copy(s.SetID[:], data[7:7+setIDSize])
+	copy(s.Digest[:], data[7+setIDSize:shareHeaderSize])
+	s.Data = append([]byte(nil), data[shareHeaderSize:]...)
+	return nil
+}
+
+// Split divides the key material of address into n shares, any threshold of
+// which reconstruct it with Combine.
+func Split(address *account.NetworkAddress, threshold, n int) ([]*Share, error) {
+	if threshold < 2 || threshold > n || n > MaxShares {
+		return nil, fmt.Errorf("%w: %d of %d", ErrInvalidThreshold, threshold, n)
+	}
+	secret, err := marshalPayload(address)
+	if err != nil {
+		return nil, err
+	}
+
+	setID, err := newSetID()
+	if err != nil {
+		return nil, err
+	}
+	digest := blake3.Sum256(secret)
+	shares := make([]*Share, n)
+	for i := range shares {
+		shares[i] = &Share{
+			SetID:     setID,
+			Threshold: byte(threshold),
+			X:         byte(i + 1),
+			Digest:    digest,
+			Data:      make([]byte, len(secret)),
+		}
+	}
+	if err := addPolynomials(shares, secret); err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+// Combine reconstructs the address from at least threshold shares of the same set.
+func Combine(shares []*Share) (*account.NetworkAddress, error) {
+	secret, err := combine(shares)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalPayload(secret)
+}
+
+// Refresh re-randomizes a complete share set without reconstructing the
+// secret: every share is offset by a fresh sharing of zero. The refreshed
+// shares form a new set, so they cannot be combined with the old ones and
+// leaked old shares become useless.
+func Refresh(shares []*Share) ([]*Share, error) {
+	if err := checkShareSet(shares); err != nil {
+		return nil, err
+	}
+
+	setID, err := newSetID()
+	if err != nil {
+		return nil, err
+	}
+	refreshed := make([]*Share, len(shares))
+	for i, share := range shares {
+		refreshed[i] = &Share{
+			SetID:     setID,
+			Threshold: share.Threshold,
+			X:         share.X,
+			Digest:    share.Digest,
+			Data:      append([]byte(nil), share.Data...),
+		}
+	}
+
+	if err := addPolynomials(refreshed, nil); err != nil {
+		return nil, err
+	}
+	return refreshed, nil
+}
+
+func newSetID() ([setIDSize]byte, error) {
+	var setID [setIDSize]byte
+	if _, err := rand.Read(setID[:]); err != nil {
+		return setID, fmt.Errorf("error generating set id: %w", err)
+	}
+	return setID, nil
+}
+
+// addPolynomials adds, for every byte position, a random polynomial of degree
+// threshold-1 evaluated at each share's x coordinate. The constant term is the
+// corresponding byte of secret, or zero when secret is nil.
+func addPolynomials(shares []*Share, secret []byte) error {
+	threshold := int(shares[0].Threshold)
+	size := len(shares[0].Data)
+
+	coefficients := make([]byte, threshold-1)
+	defer clear(coefficients)
+
+	for i := 0; i < size; i++ {
+		if _, err := rand.Read(coefficients); err != nil {
+			return fmt.Errorf("error generating coefficients: %w", err)
+		}
+		var constant byte
+		if secret != nil {
+			constant = secret[i]
+		}
+		for _, share := range shares {
+			share.Data[i] ^= evaluate(constant, coefficients, share.X)
+		}
+	}
+	return nil
+}
+
+func combine(shares []*Share) ([]byte, error) {
+	if err := checkShareSet(shares); err != nil {
+		return nil, err
+	}
+	threshold := int(shares[0].Threshold)
+	if len(shares) < threshold {
+		return nil, fmt.Errorf("%w: have %d, need %d", ErrInsufficientShares, len(shares), threshold)
+	}
+	shares = shares[:threshold]
+
+	// Lagrange basis polynomials evaluated at zero
+	basis := make([]byte, threshold)
+	for i, si := range shares {
+		basis[i] = 1
+		for j, sj := range shares {
+			if i != j {
+				basis[i] = gfMul(basis[i], gfMul(sj.X, gfInv(sj.X^si.X)))
+			}
+		}
+	}
+
+	secret := make([]byte, len(shares[0].Data))
+	for i, share := range shares {
+		for k, y := range share.Data {
+			secret[k] ^= gfMul(basis[i], y)
+		}
+	}
+
+	digest := blake3.Sum256(secret)
+	if subtle.ConstantTimeCompare(digest[:], shares[0].Digest[:]) != 1 {
+		clear(secret)
+		return nil, fmt.Errorf("%w: reconstructed secret does not match digest", ErrInvalidShare)
+	}
+	return secret, nil
+}
+
+// checkShareSet verifies that shares are well formed, belong to the same set
+// and have distinct x coordinates.
+func checkShareSet(shares []*Share) error {
+	if len(shares) == 0 {
+		return ErrInsufficientShares
+	}
+	first := shares[0]
+	if first.Threshold < 2 {
+		return ErrInvalidShare
+	}
+
+	seen := make(map[byte]bool, len(shares))
+	for _, share := range shares {
+		if share.SetID != first.SetID || share.Threshold != first.Threshold ||
+			share.Digest != first.Digest || len(share.Data) != len(first.Data) {
+			return ErrShareMismatch
+		}
+		if share.X == 0 || seen[share.X] {
+			return fmt.Errorf("%w: duplicate or zero x coordinate", ErrInvalidShare)
+		}
+		seen[share.X] = true
+	}
+	return nil
+}
+
+// evaluate computes constant + coefficients[0]·x + coefficients[1]·x² + ... in GF(2^8).
+func evaluate(constant byte, coefficients []byte, x byte) byte {
+	var y byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ coefficients[i]
+	}
+	return gfMul(y, x) ^ constant
+}
+
+// gfMul multiplies in GF(2^8) modulo x⁸+x⁴+x³+x+1 without data-dependent branches.
+func gfMul(a, b byte) byte {
+	var p byte
+	for i := 0; i < 8; i++ {
+		p ^= -(b & 1) & a
+		a = (a << 1) ^ (0x1b & -(a >> 7))
+		b >>= 1
+	}
+	return p
+}
+
+// gfInv returns the multiplicative inverse a²⁵⁴ of a non-zero a.
+func gfInv(a byte) byte {
+	r := a
+	for i := 0; i < 6; i++ {
+		r = gfMul(gfMul(r, r), a)
+	}
+	return gfMul(r, r)
+}
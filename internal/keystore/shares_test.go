@@ -0,0 +1,121 @@
+package keystore
+
+import (
+	"testing"
+
+	"github.com/nicksrepo/padawanzero/internal/account"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGF256(t *testing.T) {
+	// Known product from FIPS-197
+	assert.Equal(t, byte(0xc1), gfMul(0x57, 0x83))
+	for a := 1; a < 256; a++ {
+		assert.Equal(t, byte(1), gfMul(byte(a), gfInv(byte(a))))
+	}
+}
+
+func TestSplitCombine(t *testing.T) {
+	na, err := account.NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+
+	shares, err := Split(na, 3, 5)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	// Any three shares reconstruct the address
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}} {
+		var picked []*Share
+		for _, i := range subset {
+			picked = append(picked, shares[i])
+		}
+		combined, err := Combine(picked)
+		require.NoError(t, err)
+		assert.True(t, na.PrivateKey.Equal(combined.PrivateKey))
+		assert.True(t, na.PublicKey.Equal(combined.PublicKey))
+		assert.Equal(t, na.QuantumKeys, combined.QuantumKeys)
+		assert.Equal(t, na.CommitmentOpening, combined.CommitmentOpening)
+	}
+
+	_, err = Combine(shares[:2])
+	assert.ErrorIs(t, err, ErrInsufficientShares)
+
+	_, err = Combine([]*Share{shares[0], shares[0], shares[1]})
+	assert.ErrorIs(t, err, ErrInvalidShare)
+
+	// A corrupted share fails the digest check
+	corrupted := *shares[1]
+	corrupted.Data = append([]byte(nil), shares[1].Data...)
+	corrupted.Data[0] ^= 0x01
+	_, err = Combine([]*Share{shares[0], &corrupted, shares[2]})
+	assert.ErrorIs(t, err, ErrInvalidShare)
+
+	other, err := Split(na, 3, 5)
+	require.NoError(t, err)
+	_, err = Combine([]*Share{shares[0], shares[1], other[2]})
+	assert.ErrorIs(t, err, ErrShareMismatch)
+}
+
+func TestSplitErrors(t *testing.T) {
+	na, err := account.NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+
+	_, err = Split(na, 1, 3)
+	assert.ErrorIs(t, err, ErrInvalidThreshold)
+	_, err = Split(na, 4, 3)
+	assert.ErrorIs(t, err, ErrInvalidThreshold)
+	_, err = Split(na, 2, MaxShares+1)
+	assert.ErrorIs(t, err, ErrInvalidThreshold)
+
+	_, err = Split(&account.NetworkAddress{}, 2, 3)
+	assert.Error(t, err)
+}
+
+func TestRefresh(t *testing.T) {
+	na, err := account.NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+
+	shares, err := Split(na, 2, 3)
+	require.NoError(t, err)
+
+	refreshed, err := Refresh(shares)
+	require.NoError(t, err)
+	assert.NotEqual(t, shares[0].Data, refreshed[0].Data)
+
+	combined, err := Combine(refreshed[1:])
+	require.NoError(t, err)
+	assert.True(t, na.PrivateKey.Equal(combined.PrivateKey))
+
+	// Old and refreshed shares do not mix
+	_, err = Combine([]*Share{shares[0], refreshed[1]})
+	assert.ErrorIs(t, err, ErrShareMismatch)
+}
+
+func TestShareMarshalBinary(t *testing.T) {
+	na, err := account.NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+
+	shares, err := Split(na, 2, 2)
+	require.NoError(t, err)
+
+	decoded := make([]*Share, len(shares))
+	for i, share := range shares {
+		data, err := share.MarshalBinary()
+		require.NoError(t, err)
+		decoded[i] = new(Share)
+		require.NoError(t, decoded[i].UnmarshalBinary(data))
+		assert.Equal(t, share, decoded[i])
+	}
+
+	combined, err := Combine(decoded)
+	require.NoError(t, err)
+	assert.True(t, na.PrivateKey.Equal(combined.PrivateKey))
+
+	data, err := shares[0].MarshalBinary()
+	require.NoError(t, err)
+	assert.ErrorIs(t, new(Share).UnmarshalBinary(data[:10]), ErrInvalidFormat)
+	data[len(shareMagic)] = ShareVersion + 1
+	assert.ErrorIs(t, new(Share).UnmarshalBinary(data), ErrUnsupportedVersion)
+}
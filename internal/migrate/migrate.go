@@ -0,0 +1,203 @@
+// Package migrate upgrades persistent store files between format versions.
+//
+// Every store file starts with a small header naming its format and version:
+//
+//	magic(4) | format length(1) | format | version(4) | payload
+//
+// A Runner holds the ordered migration steps for one format and rewrites
+// files to the latest version, optionally as a dry run or after taking a backup.
+package migrate
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// InitialVersion is the version of a format before any migration step.
+const InitialVersion = 1
+
+var magic = []byte("PZDB")
+
+var (
+	ErrInvalidHeader   = errors.New("invalid store header")
+	ErrFormatMismatch  = errors.New("store format mismatch")
+	ErrNewerVersion    = errors.New("store version is newer than supported")
+	ErrInvalidSequence = errors.New("migration steps must have consecutive versions")
+)
+
+// Step migrates a payload from Version-1 to Version.
+type Step struct {
+	Version     int
+	Description string
+	Apply       func(payload []byte) ([]byte, error)
+}
+
+// Options controls how MigrateFile rewrites a store file.
+type Options struct {
+	// DryRun applies the steps in memory without touching the file.
+	DryRun bool
+	// Backup copies the original file aside before it is rewritten.
+	Backup bool
+}
+
+// Result describes a migration that was run or, for a dry run, would be run.
+type Result struct {
+	From, To   int
+	Applied    []string
+	BackupPath string
+}
+
+// Runner migrates the files of a single store format.
+type Runner struct {
+	format string
+	steps  []Step
+}
+
+// NewRunner creates a Runner for format. Steps must be ordered with
+// consecutive versions starting at InitialVersion+1.
+func NewRunner(format string, steps ...Step) (*Runner, error) {
+	if format == "" || len(format) > 255 {
+		return nil, fmt.Errorf("invalid format name %q", format)
+	}
+	for i, step := range steps {
+		if step.Version != InitialVersion+i+1 || step.Apply == nil {
+			return nil, fmt.Errorf("%w: step %d of %s", ErrInvalidSequence, i, format)
+		}
+	}
+	return &Runner{format: format, steps: steps}, nil
+}
+
+// MustNewRunner is like NewRunner but panics on invalid steps. It is meant
+// for package-level runner declarations.
+func MustNewRunner(format string, steps ...Step) *Runner {
+	r, err := NewRunner(format, steps...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Format returns the store format the runner migrates.
+func (r *Runner) Format() string {
+	return r.format
+}
+
+// LatestVersion returns the version files are migrated to.
+func (r *Runner) LatestVersion() int {
+	return InitialVersion + len(r.steps)
+}
+
+// Encode prefixes payload with a header for the latest version.
+func (r *Runner) Encode(payload []byte) []byte {
+	return Encode(r.format, r.LatestVersion(), payload)
+}
+
+// Migrate applies the steps needed to bring payload from version to the latest version.
+func (r *Runner) Migrate(payload []byte, version int) ([]byte, *Result, error) {
+	if version < InitialVersion {
+		return nil, nil, fmt.Errorf("%w: version %d", ErrInvalidHeader, version)
+	}
+	if version > r.LatestVersion() {
+		return nil, nil, fmt.Errorf("%w: %s version %d, latest %d", ErrNewerVersion, r.format, version, r.LatestVersion())
+	}
+
+	result := &Result{From: version, To: version}
+	for _, step := range r.steps[version-InitialVersion:] {
+		migrated, err := step.Apply(payload)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error migrating %s to version %d: %w", r.format, step.Version, err)
+		}
+		payload = migrated
+		result.To = step.Version
+		result.Applied = append(result.Applied, step.Description)
+	}
+	return payload, result, nil
+}
+
+// MigrateFile migrates the store file at path to the latest version.
+func (r *Runner) MigrateFile(path string, opts Options) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	format, version, payload, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if format != r.format {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrFormatMismatch, r.format, format)
+	}
+
+	migrated, result, err := r.Migrate(payload, version)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun || result.From == result.To {
+		return result, nil
+	}
+
+	if opts.Backup {
+		result.BackupPath = fmt.Sprintf("%s.v%d.bak", path, result.From)
+		if err := writeFileAtomic(result.BackupPath, data); err != nil {
+			return nil, fmt.Errorf("error writing backup: %w", err)
+		}
+	}
+
+	if err := writeFileAtomic(path, Encode(r.format, result.To, migrated)); err != nil {
+		return nil, fmt.Errorf("error writing migrated store: %w", err)
+	}
+	return result, nil
+}
+
+// Encode prefixes payload with a store header.
+func Encode(format string, version int, payload []byte) []byte {
+	buf := make([]byte, 0, len(magic)+1+len(format)+4+len(payload))
+	buf = append(buf, magic...)
+	buf = append(buf, byte(len(format)))
+	buf = append(buf, format...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(version))
+	return append(buf, payload...)
+}
+
+// Decode splits a store file into its format, version and payload.
+func Decode(data []byte) (string, int, []byte, error) {
+	if len(data) < len(magic)+1 || !bytes.Equal(data[:len(magic)], magic) {
+		return "", 0, nil, ErrInvalidHeader
+	}
+	data = data[len(magic):]
+
+	n := int(data[0])
+	if len(data) < 1+n+4 {
+		return "", 0, nil, ErrInvalidHeader
+	}
+	format := string(data[1 : 1+n])
+	version := binary.BigEndian.Uint32(data[1+n:])
+	return format, int(version), data[1+n+4:], nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it into place.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,151 @@
+package migrate
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSteps() []Step {
+	return []Step{
+		{
+			Version:     2,
+			Description: "uppercase payload",
+			Apply: func(payload []byte) ([]byte, error) {
+				return bytes.ToUpper(payload), nil
+			},
+		},
+		{
+			Version:     3,
+			Description: "append suffix",
+			Apply: func(payload []byte) ([]byte, error) {
+				return append(payload, "-v3"...), nil
+			},
+		},
+	}
+}
+
+func writeStore(t *testing.T, format string, version int, payload string) string {
+	path := filepath.Join(t.TempDir(), "store.db")
+	require.NoError(t, os.WriteFile(path, Encode(format, version, []byte(payload)), 0600))
+	return path
+}
+
+func TestNewRunner(t *testing.T) {
+	r, err := NewRunner("test", testSteps()...)
+	require.NoError(t, err)
+	assert.Equal(t, "test", r.Format())
+	assert.Equal(t, 3, r.LatestVersion())
+
+	_, err = NewRunner("test", testSteps()[1])
+	assert.ErrorIs(t, err, ErrInvalidSequence)
+
+	_, err = NewRunner("")
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { MustNewRunner("test", testSteps()[1]) })
+}
+
+func TestEncodeDecode(t *testing.T) {
+	format, version, payload, err := Decode(Encode("test", 7, []byte("payload")))
+	require.NoError(t, err)
+	assert.Equal(t, "test", format)
+	assert.Equal(t, 7, version)
+	assert.Equal(t, []byte("payload"), payload)
+
+	_, _, _, err = Decode([]byte("garbage"))
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+
+	_, _, _, err = Decode(append(append([]byte(nil), magic...), 10, 't'))
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+}
+
+func TestMigrate(t *testing.T) {
+	r := MustNewRunner("test", testSteps()...)
+
+	payload, result, err := r.Migrate([]byte("data"), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("DATA-v3"), payload)
+	assert.Equal(t, 1, result.From)
+	assert.Equal(t, 3, result.To)
+	assert.Equal(t, []string{"uppercase payload", "append suffix"}, result.Applied)
+
+	payload, result, err = r.Migrate([]byte("data"), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data-v3"), payload)
+	assert.Len(t, result.Applied, 1)
+
+	_, _, err = r.Migrate([]byte("data"), 4)
+	assert.ErrorIs(t, err, ErrNewerVersion)
+
+	_, _, err = r.Migrate([]byte("data"), 0)
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+
+	failing := MustNewRunner("test", Step{
+		Version: 2,
+		Apply: func([]byte) ([]byte, error) {
+			return nil, errors.New("boom")
+		},
+	})
+	_, _, err = failing.Migrate([]byte("data"), 1)
+	assert.ErrorContains(t, err, "boom")
+}
+
+func TestMigrateFile(t *testing.T) {
+	r := MustNewRunner("test", testSteps()...)
+	path := writeStore(t, "test", 1, "data")
+
+	result, err := r.MigrateFile(path, Options{Backup: true})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.To)
+	assert.Equal(t, path+".v1.bak", result.BackupPath)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	format, version, payload, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, "test", format)
+	assert.Equal(t, 3, version)
+	assert.Equal(t, []byte("DATA-v3"), payload)
+
+	backup, err := os.ReadFile(result.BackupPath)
+	require.NoError(t, err)
+	assert.Equal(t, Encode("test", 1, []byte("data")), backup)
+
+	// Already up to date
+	result, err = r.MigrateFile(path, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.From)
+	assert.Empty(t, result.Applied)
+}
+
+func TestMigrateFileDryRun(t *testing.T) {
+	r := MustNewRunner("test", testSteps()...)
+	path := writeStore(t, "test", 1, "data")
+
+	result, err := r.MigrateFile(path, Options{DryRun: true, Backup: true})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.To)
+	assert.Len(t, result.Applied, 2)
+	assert.Empty(t, result.BackupPath)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, Encode("test", 1, []byte("data")), data)
+
+	_, err = os.Stat(path + ".v1.bak")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestMigrateFileFormatMismatch(t *testing.T) {
+	r := MustNewRunner("test", testSteps()...)
+	path := writeStore(t, "other", 1, "data")
+
+	_, err := r.MigrateFile(path, Options{})
+	assert.ErrorIs(t, err, ErrFormatMismatch)
+}
@@ -0,0 +1,10 @@
+package state
+
+import "github.com/nicksrepo/padawanzero/internal/migrate"
+
+// Migrations for the persistent formats owned by this package. Append a
+// migrate.Step whenever the on-disk layout of a format changes.
+var (
+	NonceStoreMigrations = migrate.MustNewRunner("nonces")
+	SnapshotMigrations   = migrate.MustNewRunner("snapshot")
+)
@@ -2,10 +2,14 @@ package state
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/nicksrepo/padawanzero/internal/common"
+
 	"github.com/zeebo/blake3"
 )
 
@@ -25,8 +29,40 @@ var (
 	nonces      = make(map[string]Nonce)
 	noncesMutex sync.RWMutex
 	hashContext = blake3.New()
+
+	clock     common.Clock = common.SystemClock
+	clockSkew int64        // Tolerated clock skew in seconds
+
+	prunerRunning atomic.Bool
 )
 
+// SetClock replaces the time source used for nonce and freshness checks.
+func SetClock(c common.Clock) {
+	noncesMutex.Lock()
+	defer noncesMutex.Unlock()
+	clock = c
+}
+
+// SetClockSkewTolerance sets how far timestamps may drift from the local
+// clock, in either direction, before they are considered expired or invalid.
+func SetClockSkewTolerance(skew time.Duration) {
+	noncesMutex.Lock()
+	defer noncesMutex.Unlock()
+	clockSkew = int64(skew / time.Second)
+}
+
+// IsFresh reports whether timestamp (Unix seconds) is neither older than
+// lifetime nor in the future, allowing for the configured clock skew.
+func IsFresh(timestamp int64, lifetime time.Duration) bool {
+	noncesMutex.RLock()
+	defer noncesMutex.RUnlock()
+	return isFresh(clock.Now().Unix(), timestamp, int64(lifetime/time.Second))
+}
+
+func isFresh(now, timestamp, lifetime int64) bool {
+	return now-timestamp <= lifetime+clockSkew && timestamp-now <= clockSkew
+}
+
 // GenerateOrUpdateNonce creates or updates a nonce for the given address.
 func GenerateOrUpdateNonce(address string) *Nonce {
 	noncesMutex.Lock()
@@ -35,7 +71,7 @@ func GenerateOrUpdateNonce(address string) *Nonce {
 	// Check if a nonce already exists for the address
 	if nonce, exists := nonces[address]; exists {
 		// If nonce exists and is not expired, return it
-		if time.Now().Unix()-nonce.Timestamp <= nonceLifetime {
+		if clock.Now().Unix()-nonce.Timestamp <= nonceLifetime {
 			return &nonce
 		}
 	}
@@ -48,7 +84,7 @@ func GenerateOrUpdateNonce(address string) *Nonce {
 	}
 
 	hash := generateNonceHash(address, value)
-	timestamp := time.Now().Unix()
+	timestamp := clock.Now().Unix()
 
 	nonce := Nonce{
 		Address:   address,
@@ -63,6 +99,7 @@ func GenerateOrUpdateNonce(address string) *Nonce {
 }
 
 // ValidateNonce checks if a nonce associated with the address is valid.
+// Expiry allows for the configured clock-skew tolerance.
 func ValidateNonce(address string, nonce Nonce) bool {
 	noncesMutex.RLock()
 	defer noncesMutex.RUnlock()
@@ -70,24 +107,67 @@ func ValidateNonce(address string, nonce Nonce) bool {
 	if storedNonce, exists := nonces[address]; exists {
 		return bytes.Equal(nonce.Value, storedNonce.Value) &&
 			bytes.Equal(nonce.Hash, storedNonce.Hash) &&
-			time.Now().Unix()-storedNonce.Timestamp <= nonceLifetime
+			isFresh(clock.Now().Unix(), storedNonce.Timestamp, nonceLifetime)
 	}
 	return false
 }
 
+// ConsumeNonce checks the nonce value issued for address and removes it, so
+// each nonce can authorize at most one request.
+func ConsumeNonce(address string, value []byte) bool {
+	noncesMutex.Lock()
+	defer noncesMutex.Unlock()
+
+	storedNonce, exists := nonces[address]
+	if !exists {
+		return false
+	}
+
+	valid := bytes.Equal(value, storedNonce.Value) &&
+		bytes.Equal(generateNonceHash(address, value), storedNonce.Hash) &&
+		isFresh(clock.Now().Unix(), storedNonce.Timestamp, nonceLifetime)
+	if valid {
+		delete(nonces, address)
+	}
+	return valid
+}
+
 // PruneExpiredNonces removes expired nonces from the map.
 func PruneExpiredNonces() {
 	noncesMutex.Lock()
 	defer noncesMutex.Unlock()
 
-	currentTimestamp := time.Now().Unix()
+	currentTimestamp := clock.Now().Unix()
 	for address, nonce := range nonces {
-		if currentTimestamp-nonce.Timestamp > nonceLifetime {
+		if currentTimestamp-nonce.Timestamp > nonceLifetime+clockSkew {
 			delete(nonces, address)
 		}
 	}
 }
 
+// RunNoncePruner prunes expired nonces every interval until ctx is done.
+func RunNoncePruner(ctx context.Context, interval time.Duration) {
+	prunerRunning.Store(true)
+	defer prunerRunning.Store(false)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			PruneExpiredNonces()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// NoncePrunerRunning reports whether RunNoncePruner is running.
+func NoncePrunerRunning() bool {
+	return prunerRunning.Load()
+}
+
 // generateNonceHash generates a hash for a given nonce value using Blake3 context.
 func generateNonceHash(address string, value []byte) []byte {
 	hashContext.Reset()
@@ -2,8 +2,11 @@ package state
 
 import (
 	"bytes"
+	"context"
 	"testing"
 	"time"
+
+	"github.com/nicksrepo/padawanzero/internal/common"
 )
 
 func TestGenerateOrUpdateNonce(t *testing.T) {
@@ -58,6 +61,31 @@ func TestValidateNonce(t *testing.T) {
 	}
 }
 
+func TestConsumeNonce(t *testing.T) {
+	address := "consume_address"
+	nonce := GenerateOrUpdateNonce(address)
+	if nonce == nil {
+		t.Fatal("Failed to generate nonce")
+	}
+
+	// Wrong value is rejected without consuming the nonce
+	if ConsumeNonce(address, make([]byte, nonceSize)) {
+		t.Error("Invalid nonce value should not be consumed")
+	}
+
+	if !ConsumeNonce(address, nonce.Value) {
+		t.Error("Valid nonce should be consumed")
+	}
+
+	// Replays are rejected
+	if ConsumeNonce(address, nonce.Value) {
+		t.Error("Nonce should only be consumed once")
+	}
+	if ValidateNonce(address, *nonce) {
+		t.Error("Consumed nonce should no longer be valid")
+	}
+}
+
 func TestPruneExpiredNonces(t *testing.T) {
 	address1 := "address1"
 	address2 := "address2"
@@ -87,3 +115,133 @@ func TestPruneExpiredNonces(t *testing.T) {
 		t.Error("Non-expired nonce should still be valid")
 	}
 }
+
+func TestNonceExpiryWithClock(t *testing.T) {
+	c := common.NewManualClock(time.Unix(1700000000, 0))
+	SetClock(c)
+	defer SetClock(common.SystemClock)
+
+	address := "clock_address"
+	nonce := GenerateOrUpdateNonce(address)
+	if nonce == nil {
+		t.Fatal("Failed to generate nonce")
+	}
+
+	c.Advance(nonceLifetime * time.Second)
+	if !ValidateNonce(address, *nonce) {
+		t.Error("Nonce should be valid at the end of its lifetime")
+	}
+
+	c.Advance(time.Second)
+	if ValidateNonce(address, *nonce) {
+		t.Error("Nonce should have expired")
+	}
+
+	// Expired nonces are replaced
+	renewed := GenerateOrUpdateNonce(address)
+	if bytes.Equal(nonce.Value, renewed.Value) {
+		t.Error("Expired nonce should have been replaced")
+	}
+}
+
+func TestClockSkewTolerance(t *testing.T) {
+	c := common.NewManualClock(time.Unix(1700000000, 0))
+	SetClock(c)
+	SetClockSkewTolerance(30 * time.Second)
+	defer SetClock(common.SystemClock)
+	defer SetClockSkewTolerance(0)
+
+	address := "skew_address"
+	nonce := GenerateOrUpdateNonce(address)
+	if nonce == nil {
+		t.Fatal("Failed to generate nonce")
+	}
+
+	c.Advance((nonceLifetime + 30) * time.Second)
+	if !ValidateNonce(address, *nonce) {
+		t.Error("Nonce should be valid within the skew tolerance")
+	}
+
+	PruneExpiredNonces()
+	if !ValidateNonce(address, *nonce) {
+		t.Error("Nonce within the skew tolerance should not be pruned")
+	}
+
+	c.Advance(time.Second)
+	if ValidateNonce(address, *nonce) {
+		t.Error("Nonce should have expired beyond the skew tolerance")
+	}
+}
+
+func TestIsFresh(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	SetClock(common.NewManualClock(now))
+	SetClockSkewTolerance(10 * time.Second)
+	defer SetClock(common.SystemClock)
+	defer SetClockSkewTolerance(0)
+
+	tests := []struct {
+		name      string
+		timestamp int64
+		want      bool
+	}{
+		{"Current", now.Unix(), true},
+		{"Within lifetime", now.Unix() - 60, true},
+		{"Expired within skew", now.Unix() - 65, true},
+		{"Expired", now.Unix() - 71, false},
+		{"Future within skew", now.Unix() + 10, true},
+		{"Future", now.Unix() + 11, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsFresh(tt.timestamp, time.Minute); got != tt.want {
+				t.Errorf("IsFresh() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunNoncePruner(t *testing.T) {
+	c := common.NewManualClock(time.Unix(1700000000, 0))
+	SetClock(c)
+	defer SetClock(common.SystemClock)
+
+	address := "pruner_address"
+	nonce := GenerateOrUpdateNonce(address)
+	c.Advance((nonceLifetime + 1) * time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		RunNoncePruner(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		noncesMutex.RLock()
+		_, exists := nonces[address]
+		noncesMutex.RUnlock()
+		if !exists {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expired nonce was not pruned")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if !NoncePrunerRunning() {
+		t.Error("Pruner should report running")
+	}
+	if ValidateNonce(address, *nonce) {
+		t.Error("Pruned nonce should not be valid")
+	}
+
+	cancel()
+	<-done
+	if NoncePrunerRunning() {
+		t.Error("Pruner should report stopped")
+	}
+}
@@ -0,0 +1,257 @@
+package state
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"github.com/nicksrepo/padawanzero/internal/migrate"
+
+	"github.com/zeebo/blake3"
+	"gonum.org/v1/gonum/blas/blas64"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Snapshot layout, all integers little-endian:
+//
+//	migrate header | rows(8) | cols(8) | chunk size(8) | chunk count(8) | chunk checksums(32 each) | padding | data
+//
+// The data section starts on a page boundary and holds the row-major float64
+// values in little-endian order, so it can be memory mapped and used as the
+// matrix backing array directly. Each chunk of the data has a Blake3 checksum.
+const (
+	snapshotAlignment = 4096
+	snapshotChunkSize = 4 << 20 // bytes
+	checksumSize      = 32
+)
+
+var (
+	ErrSnapshotChecksum = errors.New("snapshot chunk checksum mismatch")
+	ErrSnapshotFormat   = errors.New("invalid snapshot format")
+)
+
+var littleEndian = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+// snapshotHeader describes the layout of a snapshot file.
+type snapshotHeader struct {
+	rows, cols int
+	chunkSize  int
+	checksums  [][]byte
+	dataOffset int
+}
+
+// WriteSnapshot writes the matrix to w. On little-endian hosts with a
+// contiguous matrix the backing array is written without copying.
+func WriteSnapshot(w io.Writer, m *Matrix) error {
+	raw := m.Data.RawMatrix()
+	if raw.Rows == 0 || raw.Cols == 0 {
+		return fmt.Errorf("%w: empty matrix", ErrSnapshotFormat)
+	}
+
+	var data []byte
+	if littleEndian && raw.Stride == raw.Cols {
+		data = float64Bytes(raw.Data[:raw.Rows*raw.Cols])
+	} else {
+		data = encodeFloat64s(raw)
+	}
+
+	checksums := make([][]byte, 0, (len(data)+snapshotChunkSize-1)/snapshotChunkSize)
+	for offset := 0; offset < len(data); offset += snapshotChunkSize {
+		sum := blake3.Sum256(data[offset:min(offset+snapshotChunkSize, len(data))])
+		checksums = append(checksums, sum[:])
+	}
+
+	header := SnapshotMigrations.Encode(nil)
+	header = binary.LittleEndian.AppendUint64(header, uint64(raw.Rows))
+	header = binary.LittleEndian.AppendUint64(header, uint64(raw.Cols))
+	header = binary.LittleEndian.AppendUint64(header, snapshotChunkSize)
+	header = binary.LittleEndian.AppendUint64(header, uint64(len(checksums)))
+	for _, sum := range checksums {
+		header = append(header, sum...)
+	}
+	header = append(header, make([]byte, alignUp(len(header))-len(header))...)
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// ReadSnapshot reads a snapshot written by WriteSnapshot into memory,
+// verifying every chunk checksum.
+func ReadSnapshot(r io.Reader) (*Matrix, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := parseSnapshotHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	body := data[h.dataOffset:]
+	if err := h.verify(body); err != nil {
+		return nil, err
+	}
+
+	// Alias the read buffer when its layout already matches the host
+	var values []float64
+	if littleEndian && uintptr(unsafe.Pointer(unsafe.SliceData(body)))%8 == 0 {
+		values = bytesFloat64s(body, h.rows*h.cols)
+	} else {
+		values = decodeFloat64s(body, h.rows*h.cols)
+	}
+	return &Matrix{Data: mat.NewDense(h.rows, h.cols, values)}, nil
+}
+
+// SaveSnapshot atomically writes the matrix to a snapshot file at path.
+func SaveSnapshot(path string, m *Matrix) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := WriteSnapshot(tmp, m); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// MappedSnapshot is a snapshot restored by memory mapping its file. The
+// matrix data aliases the mapping; writes to it are private to the process.
+// Close must be called once the matrix is no longer used.
+type MappedSnapshot struct {
+	Matrix *Matrix
+	unmap  func() error
+}
+
+// Close releases the mapping. The matrix must not be used afterwards.
+func (s *MappedSnapshot) Close() error {
+	if s.unmap == nil {
+		return nil
+	}
+	unmap := s.unmap
+	s.unmap = nil
+	s.Matrix = nil
+	return unmap()
+}
+
+func parseSnapshotHeader(data []byte) (*snapshotHeader, error) {
+	format, version, payload, err := migrate.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if format != SnapshotMigrations.Format() {
+		return nil, fmt.Errorf("%w: %s", migrate.ErrFormatMismatch, format)
+	}
+	if version != SnapshotMigrations.LatestVersion() {
+		return nil, fmt.Errorf("%w: version %d, expected %d; migrate the snapshot first", ErrSnapshotFormat, version, SnapshotMigrations.LatestVersion())
+	}
+
+	if len(payload) < 32 {
+		return nil, ErrSnapshotFormat
+	}
+	rows := binary.LittleEndian.Uint64(payload)
+	cols := binary.LittleEndian.Uint64(payload[8:])
+	chunkSize := binary.LittleEndian.Uint64(payload[16:])
+	chunks := binary.LittleEndian.Uint64(payload[24:])
+	payload = payload[32:]
+
+	if rows == 0 || cols == 0 || rows > math.MaxInt32 || cols > math.MaxInt32 ||
+		chunkSize == 0 || chunkSize%8 != 0 || chunkSize > math.MaxInt32 ||
+		chunks > uint64(len(payload)/checksumSize) {
+		return nil, ErrSnapshotFormat
+	}
+
+	// Bound the product before computing it so it cannot wrap around
+	if rows > uint64(len(data))/8/cols {
+		return nil, ErrSnapshotFormat
+	}
+	dataSize := rows * cols * 8
+	expectedChunks := dataSize / chunkSize
+	if dataSize%chunkSize != 0 {
+		expectedChunks++
+	}
+	if chunks != expectedChunks {
+		return nil, ErrSnapshotFormat
+	}
+
+	h := &snapshotHeader{
+		rows:      int(rows),
+		cols:      int(cols),
+		chunkSize: int(chunkSize),
+		checksums: make([][]byte, chunks),
+	}
+	for i := range h.checksums {
+		h.checksums[i] = payload[i*checksumSize : (i+1)*checksumSize]
+	}
+
+	h.dataOffset = alignUp(len(data) - len(payload) + int(chunks)*checksumSize)
+	if uint64(len(data)-h.dataOffset) != dataSize {
+		return nil, ErrSnapshotFormat
+	}
+	return h, nil
+}
+
+// verify checks the checksum of every chunk of data.
+func (h *snapshotHeader) verify(data []byte) error {
+	for i, checksum := range h.checksums {
+		offset := i * h.chunkSize
+		sum := blake3.Sum256(data[offset:min(offset+h.chunkSize, len(data))])
+		if !bytes.Equal(sum[:], checksum) {
+			return fmt.Errorf("%w: chunk %d", ErrSnapshotChecksum, i)
+		}
+	}
+	return nil
+}
+
+func alignUp(n int) int {
+	return (n + snapshotAlignment - 1) / snapshotAlignment * snapshotAlignment
+}
+
+// float64Bytes reinterprets a float64 slice as its backing bytes without copying.
+func float64Bytes(data []float64) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(data))), len(data)*8)
+}
+
+// bytesFloat64s reinterprets 8-byte aligned data as a float64 slice without copying.
+func bytesFloat64s(data []byte, n int) []float64 {
+	return unsafe.Slice((*float64)(unsafe.Pointer(unsafe.SliceData(data))), n)
+}
+
+func encodeFloat64s(raw blas64.General) []byte {
+	data := make([]byte, 0, raw.Rows*raw.Cols*8)
+	for i := 0; i < raw.Rows; i++ {
+		for _, v := range raw.Data[i*raw.Stride : i*raw.Stride+raw.Cols] {
+			data = binary.LittleEndian.AppendUint64(data, math.Float64bits(v))
+		}
+	}
+	return data
+}
+
+func decodeFloat64s(data []byte, n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[i*8:]))
+	}
+	return values
+}
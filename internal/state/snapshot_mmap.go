@@ -0,0 +1,63 @@
+//go:build unix
+
+package state
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+	"gonum.org/v1/gonum/mat"
+)
+
+// MapSnapshot restores the snapshot at path by memory mapping it, so the
+// matrix is backed by the file pages instead of a copy. Chunk checksums are
+// verified unless verify is false. On big-endian hosts the data is decoded
+// into memory instead.
+func MapSnapshot(path string, verify bool) (*MappedSnapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if !littleEndian {
+		m, err := ReadSnapshot(f)
+		if err != nil {
+			return nil, err
+		}
+		return &MappedSnapshot{Matrix: m}, nil
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		return nil, ErrSnapshotFormat
+	}
+
+	// Private copy-on-write mapping, so writes to the matrix never reach the file
+	data, err := unix.Mmap(int(f.Fd()), 0, int(info.Size()), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_PRIVATE)
+	if err != nil {
+		return nil, err
+	}
+	unmap := func() error { return unix.Munmap(data) }
+
+	h, err := parseSnapshotHeader(data)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+	body := data[h.dataOffset:]
+	if verify {
+		if err := h.verify(body); err != nil {
+			unmap()
+			return nil, err
+		}
+	}
+
+	return &MappedSnapshot{
+		Matrix: &Matrix{Data: mat.NewDense(h.rows, h.cols, bytesFloat64s(body, h.rows*h.cols))},
+		unmap:  unmap,
+	}, nil
+}
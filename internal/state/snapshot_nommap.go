@@ -0,0 +1,22 @@
+//go:build !unix
+
+package state
+
+import "os"
+
+// MapSnapshot restores the snapshot at path. Memory mapping is unavailable on
+// this platform, so the data is read into memory and verify is ignored:
+// checksums are always checked.
+func MapSnapshot(path string, verify bool) (*MappedSnapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m, err := ReadSnapshot(f)
+	if err != nil {
+		return nil, err
+	}
+	return &MappedSnapshot{Matrix: m}, nil
+}
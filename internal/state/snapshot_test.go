@@ -0,0 +1,235 @@
+package state
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nicksrepo/padawanzero/internal/migrate"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func newTestMatrix(rows, cols int) *Matrix {
+	data := make([]float64, rows*cols)
+	for i := range data {
+		data[i] = float64(i) * 0.5
+	}
+	data[len(data)-1] = math.NaN()
+	return &Matrix{Data: mat.NewDense(rows, cols, data)}
+}
+
+func sameBits(a, b *mat.Dense) bool {
+	ar, ac := a.Dims()
+	br, bc := b.Dims()
+	if ar != br || ac != bc {
+		return false
+	}
+	for i := 0; i < ar; i++ {
+		for j := 0; j < ac; j++ {
+			if math.Float64bits(a.At(i, j)) != math.Float64bits(b.At(i, j)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestWriteReadSnapshot(t *testing.T) {
+	m := newTestMatrix(300, 7)
+
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameBits(m.Data, restored.Data) {
+		t.Error("Restored matrix differs from the original")
+	}
+}
+
+func TestWriteSnapshotNonContiguous(t *testing.T) {
+	m := newTestMatrix(10, 10)
+	view := &Matrix{Data: m.Data.Slice(2, 6, 3, 8).(*mat.Dense)}
+
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, view); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameBits(view.Data, restored.Data) {
+		t.Error("Restored view differs from the original")
+	}
+}
+
+func TestMapSnapshot(t *testing.T) {
+	m := newTestMatrix(1000, 3)
+	path := filepath.Join(t.TempDir(), "state.snap")
+	if err := SaveSnapshot(path, m); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := MapSnapshot(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameBits(m.Data, snap.Matrix.Data) {
+		t.Error("Mapped matrix differs from the original")
+	}
+
+	// Writes to the mapped matrix do not reach the file
+	snap.Matrix.Data.Set(0, 0, 42)
+	if err := snap.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err = MapSnapshot(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer snap.Close()
+	if snap.Matrix.Data.At(0, 0) != m.Data.At(0, 0) {
+		t.Error("Mapped snapshot file was modified")
+	}
+}
+
+func TestSnapshotChecksum(t *testing.T) {
+	m := newTestMatrix(100, 4)
+	path := filepath.Join(t.TempDir(), "state.snap")
+	if err := SaveSnapshot(path, m); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-100] ^= 0xff
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadSnapshot(bytes.NewReader(data)); !errors.Is(err, ErrSnapshotChecksum) {
+		t.Errorf("Expected checksum error, got %v", err)
+	}
+	if _, err := MapSnapshot(path, true); !errors.Is(err, ErrSnapshotChecksum) {
+		t.Errorf("Expected checksum error, got %v", err)
+	}
+}
+
+func TestSnapshotInvalidFormat(t *testing.T) {
+	if _, err := ReadSnapshot(bytes.NewReader([]byte("garbage"))); err == nil {
+		t.Error("Expected error for invalid header")
+	}
+
+	other := migrate.Encode("nonces", 1, make([]byte, 64))
+	if _, err := ReadSnapshot(bytes.NewReader(other)); !errors.Is(err, migrate.ErrFormatMismatch) {
+		t.Errorf("Expected format mismatch, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, newTestMatrix(10, 10)); err != nil {
+		t.Fatal(err)
+	}
+	truncated := buf.Bytes()[:buf.Len()-8]
+	if _, err := ReadSnapshot(bytes.NewReader(truncated)); !errors.Is(err, ErrSnapshotFormat) {
+		t.Errorf("Expected format error, got %v", err)
+	}
+}
+
+func TestSnapshotDimensionOverflow(t *testing.T) {
+	// rows*cols*8 wraps around to the size of a real 1x67194 matrix, so the
+	// body size and every chunk checksum match the forged dimensions
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, newTestMatrix(1, 67194)); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	_, _, payload, err := migrate.Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offset := len(data) - len(payload)
+	binary.LittleEndian.PutUint64(data[offset:], 1073764994)
+	binary.LittleEndian.PutUint64(data[offset+8:], 2147437309)
+
+	if _, err := ReadSnapshot(bytes.NewReader(data)); !errors.Is(err, ErrSnapshotFormat) {
+		t.Errorf("Expected format error, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "overflow.snap")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MapSnapshot(path, true); !errors.Is(err, ErrSnapshotFormat) {
+		t.Errorf("Expected format error, got %v", err)
+	}
+}
+
+func TestSnapshotChunkSizeOverflow(t *testing.T) {
+	// A chunk size that is negative as an int passes the chunk count check
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, newTestMatrix(2, 3)); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	_, _, payload, err := migrate.Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offset := len(data) - len(payload)
+	binary.LittleEndian.PutUint64(data[offset+16:], 1<<63+8)
+
+	if _, err := ReadSnapshot(bytes.NewReader(data)); !errors.Is(err, ErrSnapshotFormat) {
+		t.Errorf("Expected format error, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "chunksize.snap")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MapSnapshot(path, true); !errors.Is(err, ErrSnapshotFormat) {
+		t.Errorf("Expected format error, got %v", err)
+	}
+}
+
+func BenchmarkSaveSnapshot(b *testing.B) {
+	m := newTestMatrix(1<<20, 1)
+	path := filepath.Join(b.TempDir(), "state.snap")
+	b.SetBytes(8 << 20)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := SaveSnapshot(path, m); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMapSnapshot(b *testing.B) {
+	m := newTestMatrix(1<<20, 1)
+	path := filepath.Join(b.TempDir(), "state.snap")
+	if err := SaveSnapshot(path, m); err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(8 << 20)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		snap, err := MapSnapshot(path, true)
+		if err != nil {
+			b.Fatal(err)
+		}
+		snap.Close()
+	}
+}
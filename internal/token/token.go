@@ -0,0 +1,372 @@
+// Package token issues and verifies short-lived capability tokens for
+// authenticated addresses.
+//
+// A Service authenticates an address once through authz, which verifies its
+// ZKP against the address public key and a signature covering the whole
+// AddressInfo, checks freshness and consumes a nonce. It then issues a
+// macaroon-style token bound to the AddressID, a region and an expiry.
+// Downstream services holding the root key verify tokens with a Verifier, a
+// few keyed hashes per request instead of a ZKP verification.
+//
+// The token signature is a chain of keyed blake3 hashes: the identifier is
+// hashed under the root key and each caveat under the previous signature. A
+// holder can therefore append caveats to restrict a token, but cannot remove
+// or change any.
+package token
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nicksrepo/padawanzero/internal/account"
+	"github.com/nicksrepo/padawanzero/internal/authz"
+	"github.com/nicksrepo/padawanzero/internal/common"
+
+	"github.com/zeebo/blake3"
+)
+
+const (
+	// Version is the current token encoding version.
+	Version = 1
+
+	// RootKeySize is the length of the key tokens are signed with.
+	RootKeySize = 32
+
+	// DefaultLifetime is how long an issued token stays valid.
+	DefaultLifetime = 15 * time.Minute
+
+	// IssueResource is the resource clients sign when requesting a token.
+	IssueResource = "padawan-token/issue"
+
+	idSize        = 16
+	signatureSize = 32
+)
+
+// Caveat keys understood by Verifier. Tokens with other caveats are rejected.
+const (
+	CaveatAccount = "account"
+	CaveatRegion  = "region"
+	CaveatExpires = "expires"
+)
+
+var (
+	ErrInvalidRootKey = errors.New("root key must be 32 bytes")
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrInvalidCaveat  = errors.New("invalid caveat")
+	ErrExpired        = errors.New("token expired")
+	ErrRegionMismatch = errors.New("token is not valid in this region")
+)
+
+// Caveat is a first-party restriction on a token.
+type Caveat struct {
+	Key   string
+	Value string
+}
+
+func (c Caveat) String() string {
+	return c.Key + "=" + c.Value
+}
+
+func parseCaveat(s string) (Caveat, error) {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return Caveat{}, fmt.Errorf("%w: %q", ErrInvalidCaveat, s)
+	}
+	return Caveat{Key: key, Value: value}, nil
+}
+
+// Token is a capability token: an identifier, its caveats and the chained signature.
+type Token struct {
+	ID        []byte
+	Caveats   []Caveat
+	Signature []byte
+}
+
+// Attenuate returns a copy of t further restricted by caveats. It does not
+// need the root key.
+func (t *Token) Attenuate(caveats ...Caveat) *Token {
+	attenuated := &Token{
+		ID:        t.ID,
+		Caveats:   append(append([]Caveat(nil), t.Caveats...), caveats...),
+		Signature: t.Signature,
+	}
+	for _, caveat := range caveats {
+		attenuated.Signature = chain(attenuated.Signature, []byte(caveat.String()))
+	}
+	return attenuated
+}
+
+// MarshalBinary encodes the token as
+//
+//	version(1) | uvarint len | id | uvarint count | (uvarint len | caveat)* | signature(32)
+func (t *Token) MarshalBinary() ([]byte, error) {
+	data := []byte{Version}
+	data = binary.AppendUvarint(data, uint64(len(t.ID)))
+	data = append(data, t.ID...)
+	data = binary.AppendUvarint(data, uint64(len(t.Caveats)))
+	for _, caveat := range t.Caveats {
+		s := caveat.String()
+		data = binary.AppendUvarint(data, uint64(len(s)))
+		data = append(data, s...)
+	}
+	return append(data, t.Signature...), nil
+}
+
+// UnmarshalBinary decodes a token encoded with MarshalBinary.
+func (t *Token) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != Version {
+		return fmt.Errorf("%w: unsupported version", ErrInvalidToken)
+	}
+	r := bytes.NewReader(data[1:])
+
+	readField := func() ([]byte, error) {
+		n, err := binary.ReadUvarint(r)
+		if err != nil || n > uint64(r.Len()) {
+			return nil, fmt.Errorf("%w: truncated", ErrInvalidToken)
+		}
+		field := make([]byte, n)
+		_, err = io.ReadFull(r, field)
+		return field, err
+	}
+
+	id, err := readField()
+	if err != nil {
+		return err
+	}
+	count, err := binary.ReadUvarint(r)
+	if err != nil || count > uint64(r.Len()) {
+		return fmt.Errorf("%w: truncated", ErrInvalidToken)
+	}
+	caveats := make([]Caveat, 0, count)
+	for i := uint64(0); i < count; i++ {
+		field, err := readField()
+		if err != nil {
+			return err
+		}
+		caveat, err := parseCaveat(string(field))
+		if err != nil {
+			return err
+		}
+		caveats = append(caveats, caveat)
+	}
+	if r.Len() != signatureSize {
+		return fmt.Errorf("%w: invalid signature length", ErrInvalidToken)
+	}
+	signature := make([]byte, signatureSize)
+	if _, err := io.ReadFull(r, signature); err != nil {
+		return err
+	}
+
+	t.ID, t.Caveats, t.Signature = id, caveats, signature
+	return nil
+}
+
+// Encode returns the token as an unpadded base64url string for use in headers.
+func (t *Token) Encode() (string, error) {
+	data, err := t.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Decode parses a token produced by Encode.
+func Decode(s string) (*Token, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	t := &Token{}
+	if err := t.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// chain computes the next signature in the chain: the keyed hash of data under key.
+func chain(key, data []byte) []byte {
+	h, err := blake3.NewKeyed(key)
+	if err != nil {
+		// Keys are always 32 bytes: the root key or a previous signature
+		panic(err)
+	}
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// Config configures a Service. Zero values select the defaults.
+type Config struct {
+	// RootKey signs issued tokens and must be shared with verifiers.
+	RootKey []byte
+	// Lifetime bounds the validity of issued tokens. Defaults to DefaultLifetime.
+	Lifetime time.Duration
+	// Authenticator checks token requests. Defaults to authz.New(authz.Config{}).
+	Authenticator *authz.Authenticator
+	// Clock sets issue times. Defaults to common.SystemClock.
+	Clock common.Clock
+}
+
+// Service issues tokens to authenticated addresses.
+type Service struct {
+	rootKey       []byte
+	lifetime      time.Duration
+	authenticator *authz.Authenticator
+	clock         common.Clock
+}
+
+// NewService creates a Service from config.
+func NewService(config Config) (*Service, error) {
+	if len(config.RootKey) != RootKeySize {
+		return nil, ErrInvalidRootKey
+	}
+
+	s := &Service{
+		rootKey:       config.RootKey,
+		lifetime:      config.Lifetime,
+		authenticator: config.Authenticator,
+		clock:         config.Clock,
+	}
+	if s.lifetime <= 0 {
+		s.lifetime = DefaultLifetime
+	}
+	if s.authenticator == nil {
+		s.authenticator = authz.New(authz.Config{})
+	}
+	if s.clock == nil {
+		s.clock = common.SystemClock
+	}
+	return s, nil
+}
+
+// Issue authenticates creds, signed over IssueResource, and returns a token
+// for the address restricted to region. An empty region leaves the token
+// valid in every region.
+func (s *Service) Issue(ctx context.Context, creds *authz.Credentials, region string) (*Token, error) {
+	ctx, err := s.authenticator.Authenticate(ctx, creds, IssueResource)
+	if err != nil {
+		return nil, err
+	}
+	id, _ := authz.AddressIDFromContext(ctx)
+
+	tokenID := make([]byte, idSize)
+	if _, err := rand.Read(tokenID); err != nil {
+		return nil, fmt.Errorf("error generating token id: %w", err)
+	}
+
+	caveats := []Caveat{{Key: CaveatAccount, Value: string(id)}}
+	if region != "" {
+		caveats = append(caveats, Caveat{Key: CaveatRegion, Value: region})
+	}
+	expires := s.clock.Now().Add(s.lifetime).Unix()
+	caveats = append(caveats, Caveat{Key: CaveatExpires, Value: strconv.FormatInt(expires, 10)})
+
+	root := &Token{ID: tokenID, Signature: chain(s.rootKey, tokenID)}
+	return root.Attenuate(caveats...), nil
+}
+
+// Claims are the verified contents of a token.
+type Claims struct {
+	Account account.AddressID
+	Expires time.Time
+}
+
+// Verifier checks tokens issued by a Service sharing its root key.
+type Verifier struct {
+	rootKey []byte
+	region  string
+	clock   common.Clock
+}
+
+// NewVerifier creates a Verifier for the service region. A nil clock selects
+// common.SystemClock.
+func NewVerifier(rootKey []byte, region string, clock common.Clock) (*Verifier, error) {
+	if len(rootKey) != RootKeySize {
+		return nil, ErrInvalidRootKey
+	}
+	if clock == nil {
+		clock = common.SystemClock
+	}
+	return &Verifier{rootKey: rootKey, region: region, clock: clock}, nil
+}
+
+// Verify checks the signature chain of t and that every caveat holds.
+func (v *Verifier) Verify(t *Token) (*Claims, error) {
+	signature := chain(v.rootKey, t.ID)
+	for _, caveat := range t.Caveats {
+		signature = chain(signature, []byte(caveat.String()))
+	}
+	if subtle.ConstantTimeCompare(signature, t.Signature) != 1 {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	claims := &Claims{}
+	for _, caveat := range t.Caveats {
+		switch caveat.Key {
+		case CaveatAccount:
+			id := account.AddressID(caveat.Value)
+			if claims.Account != "" && claims.Account != id {
+				return nil, fmt.Errorf("%w: conflicting accounts", ErrInvalidCaveat)
+			}
+			claims.Account = id
+		case CaveatRegion:
+			if caveat.Value != v.region {
+				return nil, ErrRegionMismatch
+			}
+		case CaveatExpires:
+			unix, err := strconv.ParseInt(caveat.Value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidCaveat, err)
+			}
+			expires := time.Unix(unix, 0)
+			if !v.clock.Now().Before(expires) {
+				return nil, ErrExpired
+			}
+			if claims.Expires.IsZero() || expires.Before(claims.Expires) {
+				claims.Expires = expires
+			}
+		default:
+			return nil, fmt.Errorf("%w: unknown caveat %q", ErrInvalidCaveat, caveat.Key)
+		}
+	}
+
+	if claims.Account == "" || claims.Expires.IsZero() {
+		return nil, fmt.Errorf("%w: missing account or expiry", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+// Middleware verifies the bearer token of HTTP requests before passing them
+// to next, attaching the token account as the authz AddressID.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoded, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		t, err := Decode(encoded)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		claims, err := v.Verify(t)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(authz.WithAddressID(r.Context(), claims.Account)))
+	})
+}
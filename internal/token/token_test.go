@@ -0,0 +1,205 @@
+package token
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nicksrepo/padawanzero/internal/account"
+	"github.com/nicksrepo/padawanzero/internal/authz"
+	"github.com/nicksrepo/padawanzero/internal/common"
+	"github.com/nicksrepo/padawanzero/internal/state"
+	libzk13 "github.com/nicksrepo/padawanzero/zero-knowledge"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+)
+
+var rootKey = bytes.Repeat([]byte{0x42}, RootKeySize)
+
+func issueToken(t *testing.T, clock common.Clock, region string) (*Token, account.AddressID) {
+	na, err := account.NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+	require.NoError(t, na.GenerateZKP(256))
+	ai, err := na.Info()
+	require.NoError(t, err)
+
+	service, err := NewService(Config{RootKey: rootKey, Clock: clock})
+	require.NoError(t, err)
+
+	nonce := state.GenerateOrUpdateNonce(string(ai.ID()))
+	creds, err := authz.NewCredentials(na, nonce.Value, time.Now().Unix(), IssueResource)
+	require.NoError(t, err)
+
+	tok, err := service.Issue(context.Background(), creds, region)
+	require.NoError(t, err)
+
+	// The nonce was consumed, so the credentials cannot be reused
+	_, err = service.Issue(context.Background(), creds, region)
+	assert.ErrorIs(t, err, authz.ErrInvalidNonce)
+
+	return tok, ai.ID()
+}
+
+func TestIssueVerify(t *testing.T) {
+	clock := common.NewManualClock(time.Now())
+	tok, id := issueToken(t, clock, "eu-west")
+
+	v, err := NewVerifier(rootKey, "eu-west", clock)
+	require.NoError(t, err)
+
+	claims, err := v.Verify(tok)
+	require.NoError(t, err)
+	assert.Equal(t, id, claims.Account)
+	assert.Equal(t, clock.Now().Add(DefaultLifetime).Unix(), claims.Expires.Unix())
+
+	// The token survives encoding
+	encoded, err := tok.Encode()
+	require.NoError(t, err)
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, tok, decoded)
+
+	other, err := NewVerifier(rootKey, "us-east", clock)
+	require.NoError(t, err)
+	_, err = other.Verify(tok)
+	assert.ErrorIs(t, err, ErrRegionMismatch)
+
+	wrongKey, err := NewVerifier(bytes.Repeat([]byte{0x24}, RootKeySize), "eu-west", clock)
+	require.NoError(t, err)
+	_, err = wrongKey.Verify(tok)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	clock.Advance(DefaultLifetime)
+	_, err = v.Verify(tok)
+	assert.ErrorIs(t, err, ErrExpired)
+}
+
+func TestIssueRejectsForgedProof(t *testing.T) {
+	na, err := account.NewNetworkAddress(40.7128, -74.0060)
+	require.NoError(t, err)
+	require.NoError(t, na.GenerateZKP(256))
+	ai, err := na.Info()
+	require.NoError(t, err)
+
+	service, err := NewService(Config{RootKey: rootKey})
+	require.NoError(t, err)
+
+	nonce := state.GenerateOrUpdateNonce(string(ai.ID()))
+	creds, err := authz.NewCredentials(na, nonce.Value, time.Now().Unix(), IssueResource)
+	require.NoError(t, err)
+
+	forge := func(modify func(address *account.AddressInfo)) *authz.Credentials {
+		forged := *creds
+		address := *creds.Address
+		modify(&address)
+		forged.Address = &address
+		return &forged
+	}
+
+	// A made-up ZKP fails verification
+	_, err = service.Issue(context.Background(), forge(func(address *account.AddressInfo) {
+		address.ZKPProof = "deadbeef|cafebabe"
+	}), "")
+	assert.ErrorIs(t, err, authz.ErrInvalidAddress)
+
+	// So does a well-formed proof with a tampered response
+	proof, err := libzk13.ParseKnowledgeProof(ai.ZKPProof)
+	require.NoError(t, err)
+	proof.S.Add(proof.S, big.NewInt(1))
+	_, err = service.Issue(context.Background(), forge(func(address *account.AddressInfo) {
+		address.ZKPProof = proof.String()
+	}), "")
+	assert.ErrorIs(t, err, authz.ErrInvalidAddress)
+
+	// A commitment of the caller's choosing is not covered by the signature
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	chosen, err := suite.Point().Mul(suite.Scalar().Pick(suite.RandomStream()), nil).MarshalBinary()
+	require.NoError(t, err)
+	_, err = service.Issue(context.Background(), forge(func(address *account.AddressInfo) {
+		address.LocationCommitment = base64.RawStdEncoding.EncodeToString(chosen)
+	}), "")
+	assert.ErrorIs(t, err, authz.ErrInvalidSignature)
+
+	_, err = service.Issue(context.Background(), nil, "")
+	assert.ErrorIs(t, err, authz.ErrMissingCredentials)
+
+	// The rejected requests did not burn the nonce
+	_, err = service.Issue(context.Background(), creds, "")
+	assert.NoError(t, err)
+}
+
+func TestAttenuate(t *testing.T) {
+	clock := common.NewManualClock(time.Now())
+	tok, _ := issueToken(t, clock, "")
+
+	v, err := NewVerifier(rootKey, "eu-west", clock)
+	require.NoError(t, err)
+
+	// A holder can shorten the expiry without the root key
+	shortened := tok.Attenuate(Caveat{Key: CaveatExpires, Value: "0"})
+	_, err = v.Verify(shortened)
+	assert.ErrorIs(t, err, ErrExpired)
+
+	restricted := tok.Attenuate(Caveat{Key: CaveatRegion, Value: "us-east"})
+	_, err = v.Verify(restricted)
+	assert.ErrorIs(t, err, ErrRegionMismatch)
+
+	// Caveats cannot be removed or rewritten
+	stripped := *restricted
+	stripped.Caveats = restricted.Caveats[:len(restricted.Caveats)-1]
+	_, err = v.Verify(&stripped)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	rewritten := *tok
+	rewritten.Caveats = append([]Caveat(nil), tok.Caveats...)
+	rewritten.Caveats[0].Value = "someone-else"
+	_, err = v.Verify(&rewritten)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = v.Verify(tok.Attenuate(Caveat{Key: "admin", Value: "true"}))
+	assert.ErrorIs(t, err, ErrInvalidCaveat)
+}
+
+func TestMiddleware(t *testing.T) {
+	tok, id := issueToken(t, nil, "")
+	encoded, err := tok.Encode()
+	require.NoError(t, err)
+
+	v, err := NewVerifier(rootKey, "", nil)
+	require.NoError(t, err)
+
+	var gotID account.AddressID
+	handler := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID, _ = authz.AddressIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts", nil)
+	req.Header.Set("Authorization", "Bearer "+encoded)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, id, gotID)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/accounts", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestConfigErrors(t *testing.T) {
+	_, err := NewService(Config{RootKey: []byte("short")})
+	assert.ErrorIs(t, err, ErrInvalidRootKey)
+	_, err = NewVerifier(nil, "", nil)
+	assert.ErrorIs(t, err, ErrInvalidRootKey)
+
+	_, err = Decode("!!")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = Decode("AQ")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
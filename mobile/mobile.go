@@ -0,0 +1,166 @@
+// Package mobile exposes a flattened API over the address and proof
+// primitives that can be bound with gomobile. Every exported function only
+// takes and returns strings, byte slices and basic numeric types so the
+// generated iOS/Android bindings need no custom marshalling.
+package mobile
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/nicksrepo/padawanzero/internal/account"
+	libzk13 "github.com/nicksrepo/padawanzero/zero-knowledge"
+
+	jsoniter "github.com/json-iterator/go"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+	"go.dedis.ch/kyber/v3/sign/schnorr"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+var suite = edwards25519.NewBlakeSHA256Ed25519()
+
+// Bounds on the prime size of ZK13 group parameters. Smaller sizes panic or are
+// insecure, larger ones take too long to generate on a device.
+const (
+	MinBits = 256
+	MaxBits = 4096
+)
+
+// KeyPair is an Edwards25519 signing key pair in binary form.
+type KeyPair struct {
+	PrivateKey []byte
+	PublicKey  []byte
+}
+
+// proofEnvelope is the serialized form of a ZK13 proof together with the
+// public group parameters needed to verify it.
+type proofEnvelope struct {
+	P     string `json:"p"`
+	G     string `json:"g"`
+	Q     string `json:"q"`
+	R     string `json:"r"`
+	Proof string `json:"proof"`
+	Nonce string `json:"nonce"`
+}
+
+// GenerateAddress generates an address for the given coordinates and returns
+// the JSON encoded AddressInfo.
+func GenerateAddress(lat, lon float64, bits int) ([]byte, error) {
+	if err := checkBits(bits); err != nil {
+		return nil, err
+	}
+	ai, err := account.GenerateAddress(lat, lon, bits)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(ai)
+}
+
+// CreateProof proves knowledge of secret and returns the JSON encoded proof.
+func CreateProof(secret string, bits int) ([]byte, error) {
+	if err := checkBits(bits); err != nil {
+		return nil, err
+	}
+
+	zkp := libzk13.NewZK13(secret, bits)
+	params := zkp.Params()
+
+	// The verifier only accepts nonces in (1, q)
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Sub(params.Q, big.NewInt(2)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	nonce.Add(nonce, big.NewInt(2))
+
+	proof, err := zkp.Prover(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create proof: %w", err)
+	}
+
+	return json.Marshal(&proofEnvelope{
+		P:     params.P.Text(16),
+		G:     params.G.Text(16),
+		Q:     params.Q.Text(16),
+		R:     proof.R.Text(16),
+		Proof: proof.P.Text(16),
+		Nonce: proof.Nonce.Text(16),
+	})
+}
+
+func checkBits(bits int) error {
+	if bits < MinBits || bits > MaxBits {
+		return fmt.Errorf("bits must be between %d and %d", MinBits, MaxBits)
+	}
+	return nil
+}
+
+// VerifyProof checks a proof produced by CreateProof against secret. The
+// group parameters carried by the proof are rejected unless p has between
+// MinBits and MaxBits bits and they form a prime-order subgroup.
+func VerifyProof(secret string, proof []byte) (bool, error) {
+	var env proofEnvelope
+	if err := json.Unmarshal(proof, &env); err != nil {
+		return false, fmt.Errorf("invalid proof encoding: %w", err)
+	}
+
+	values := make([]*big.Int, 6)
+	for i, s := range []string{env.P, env.G, env.Q, env.R, env.Proof, env.Nonce} {
+		v, ok := new(big.Int).SetString(s, 16)
+		if !ok {
+			return false, errors.New("invalid proof encoding")
+		}
+		values[i] = v
+	}
+
+	// The group comes from the prover, so bound its size before any
+	// exponentiation and then check its structure
+	params := libzk13.Params{P: values[0], G: values[1], Q: values[2]}
+	if err := checkBits(params.P.BitLen()); err != nil {
+		return false, fmt.Errorf("invalid group: p: %w", err)
+	}
+	if params.Q.BitLen() < MinBits/2 || params.Q.BitLen() >= params.P.BitLen() {
+		return false, fmt.Errorf("invalid group: q must have between %d and %d bits", MinBits/2, params.P.BitLen()-1)
+	}
+
+	zkp, err := libzk13.NewZK13WithParams(secret, params)
+	if err != nil {
+		return false, fmt.Errorf("invalid group: %w", err)
+	}
+
+	return zkp.Verifier(&libzk13.Proof{R: values[3], P: values[4], Nonce: values[5]}), nil
+}
+
+// GenerateKeyPair generates a new Edwards25519 signing key pair.
+func GenerateKeyPair() (*KeyPair, error) {
+	privateKey := suite.Scalar().Pick(suite.RandomStream())
+	publicKey := suite.Point().Mul(privateKey, nil)
+
+	privateKeyBytes, err := privateKey.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	publicKeyBytes, err := publicKey.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyPair{PrivateKey: privateKeyBytes, PublicKey: publicKeyBytes}, nil
+}
+
+// Sign produces a Schnorr signature over message with privateKey.
+func Sign(privateKey, message []byte) ([]byte, error) {
+	scalar := suite.Scalar()
+	if err := scalar.UnmarshalBinary(privateKey); err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return schnorr.Sign(suite, scalar, message)
+}
+
+// VerifySignature reports whether signature is a valid signature over
+// message by publicKey.
+func VerifySignature(publicKey, message, signature []byte) bool {
+	return schnorr.VerifyWithChecks(suite, publicKey, message, signature) == nil
+}
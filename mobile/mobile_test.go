@@ -0,0 +1,102 @@
+package mobile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nicksrepo/padawanzero/internal/account"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAddress(t *testing.T) {
+	data, err := GenerateAddress(40.7128, -74.0060, 256)
+	require.NoError(t, err)
+
+	ai := &account.AddressInfo{}
+	require.NoError(t, json.Unmarshal(data, ai))
+	assert.NotEmpty(t, ai.PublicKey)
+	assert.NotEmpty(t, ai.ZKPProof)
+
+	_, err = GenerateAddress(91, 0, 256)
+	assert.Error(t, err)
+}
+
+func TestCreateVerifyProof(t *testing.T) {
+	proof, err := CreateProof("secret", 256)
+	require.NoError(t, err)
+
+	ok, err := VerifyProof("secret", proof)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = VerifyProof("wrong secret", proof)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = VerifyProof("secret", []byte("invalid"))
+	assert.Error(t, err)
+
+	_, err = CreateProof("secret", 0)
+	assert.Error(t, err)
+}
+
+func TestBitsBounds(t *testing.T) {
+	// Sizes that used to panic inside the ZK13 setup return errors instead
+	for _, bits := range []int{-1, 0, 1, 2, MinBits - 1, MaxBits + 1} {
+		_, err := CreateProof("secret", bits)
+		assert.Error(t, err, "bits %d", bits)
+		_, err = GenerateAddress(40.7128, -74.0060, bits)
+		assert.Error(t, err, "bits %d", bits)
+	}
+}
+
+func TestVerifyProofRejectsUnsafeGroups(t *testing.T) {
+	proof, err := CreateProof("secret", MinBits)
+	require.NoError(t, err)
+	var env proofEnvelope
+	require.NoError(t, json.Unmarshal(proof, &env))
+
+	// The identity verifies for any secret unless the group and r are checked
+	degenerate := proofEnvelope{P: env.P, G: env.G, Q: env.Q, R: "1", Proof: "1", Nonce: "2"}
+
+	tests := []struct {
+		name string
+		env  proofEnvelope
+	}{
+		{"Identity r", degenerate},
+		{"Tiny group", proofEnvelope{P: "3", G: "2", Q: "5", R: "1", Proof: "1", Nonce: "2"}},
+		{"Oversized p", proofEnvelope{P: strings.Repeat("f", MaxBits/4+1), G: "2", Q: env.Q, R: "2", Proof: "2", Nonce: "2"}},
+		{"Small q", proofEnvelope{P: env.P, G: env.G, Q: "b", R: env.R, Proof: env.Proof, Nonce: "2"}},
+		{"Composite p", proofEnvelope{P: "f" + env.P[1:] + "0", G: env.G, Q: env.Q, R: env.R, Proof: env.Proof, Nonce: env.Nonce}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(&tt.env)
+			require.NoError(t, err)
+			ok, _ := VerifyProof("any secret at all", data)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	kp, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	message := []byte("hello padawan")
+	sig, err := Sign(kp.PrivateKey, message)
+	require.NoError(t, err)
+
+	assert.True(t, VerifySignature(kp.PublicKey, message, sig))
+	assert.False(t, VerifySignature(kp.PublicKey, []byte("tampered"), sig))
+
+	other, err := GenerateKeyPair()
+	require.NoError(t, err)
+	assert.False(t, VerifySignature(other.PublicKey, message, sig))
+
+	_, err = Sign([]byte("short"), message)
+	assert.Error(t, err)
+}
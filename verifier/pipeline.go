@@ -0,0 +1,103 @@
+package verifier
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Result is the outcome of verifying the Index-th Address read by a Pipeline.
+type Result struct {
+	Index   int
+	Address *Address
+	Report  *Report
+}
+
+// Pipeline verifies a stream of Addresses across a bounded worker pool and
+// emits the results in input order. All workers share one Verifier.
+type Pipeline struct {
+	verifier *Verifier
+	workers  int
+}
+
+type job struct {
+	index  int
+	ai     *Address
+	result chan Result
+}
+
+// NewPipeline creates a Pipeline with the given number of workers. A
+// non-positive count uses runtime.GOMAXPROCS(0).
+func NewPipeline(workers int) *Pipeline {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return &Pipeline{verifier: New(), workers: workers}
+}
+
+// Run verifies the Addresses received on in until it is closed or ctx is
+// done. The returned channel is closed once every result has been emitted.
+// At most twice the worker count of results are buffered, so a slow consumer
+// applies backpressure to the input.
+func (p *Pipeline) Run(ctx context.Context, in <-chan *Address) <-chan Result {
+	jobs := make(chan job)
+	pending := make(chan chan Result, p.workers)
+	out := make(chan Result)
+
+	var wg sync.WaitGroup
+	wg.Add(p.workers)
+	for i := 0; i < p.workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				j.result <- Result{Index: j.index, Address: j.ai, Report: p.verifier.Verify(j.ai)}
+			}
+		}()
+	}
+
+	// Dispatch jobs, queueing their result channels in input order
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		for index := 0; ; index++ {
+			var ai *Address
+			var ok bool
+			select {
+			case ai, ok = <-in:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+
+			result := make(chan Result, 1)
+			select {
+			case pending <- result:
+			case <-ctx.Done():
+				return
+			}
+			jobs <- job{index: index, ai: ai, result: result}
+		}
+	}()
+
+	// Emit results in the order their jobs were dispatched
+	go func() {
+		defer close(out)
+		defer wg.Wait()
+		for result := range pending {
+			select {
+			case r := <-result:
+				select {
+				case out <- r:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
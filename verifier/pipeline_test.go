@@ -0,0 +1,95 @@
+package verifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineOrder(t *testing.T) {
+	valid := generateAddress(t)
+	invalid := &Address{}
+
+	// Every third input fails, half of those because the Address is nil
+	inputs := make([]*Address, 200)
+	for i := range inputs {
+		switch {
+		case i%6 == 0:
+			inputs[i] = nil
+		case i%3 == 0:
+			inputs[i] = invalid
+		default:
+			inputs[i] = valid
+		}
+	}
+
+	in := make(chan *Address)
+	go func() {
+		defer close(in)
+		for _, ai := range inputs {
+			in <- ai
+		}
+	}()
+
+	i := 0
+	for result := range NewPipeline(4).Run(context.Background(), in) {
+		assert.Equal(t, i, result.Index)
+		assert.Same(t, inputs[i], result.Address)
+		assert.Equal(t, i%3 != 0, result.Report.Valid())
+		if i%6 == 0 {
+			assert.ErrorIs(t, result.Report.Err(), ErrMissingAddress)
+		}
+		i++
+	}
+	assert.Equal(t, len(inputs), i)
+}
+
+func TestPipelineCancel(t *testing.T) {
+	valid := generateAddress(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// An input that is never closed
+	in := make(chan *Address)
+	go func() {
+		for {
+			select {
+			case in <- valid:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	out := NewPipeline(2).Run(ctx, in)
+
+	for i := 0; i < 10; i++ {
+		result := <-out
+		assert.Equal(t, i, result.Index)
+	}
+	cancel()
+
+	// The output is closed after cancellation
+	for range out {
+	}
+}
+
+func BenchmarkPipeline(b *testing.B) {
+	ai := generateAddress(b)
+
+	in := make(chan *Address)
+	go func() {
+		defer close(in)
+		for i := 0; i < b.N; i++ {
+			in <- ai
+		}
+	}()
+
+	b.ResetTimer()
+	for result := range NewPipeline(0).Run(context.Background(), in) {
+		if !result.Report.Valid() {
+			b.Fatal(result.Report.Err())
+		}
+	}
+}
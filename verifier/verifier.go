@@ -0,0 +1,234 @@
+// Package verifier checks serialized AddressInfos offline. It has no network
+// or storage dependencies so it can be embedded in air-gapped audit tooling.
+//
+// Keys and commitments must be valid Edwards25519 points and the nonce must
+// have the expected sizes. The ZKP is a ZK13 proof of knowledge carrying its
+// own group parameters; the group is validated and the proof is checked
+// against the address public key it is bound to.
+package verifier
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	libzk13 "github.com/nicksrepo/padawanzero/zero-knowledge"
+
+	jsoniter "github.com/json-iterator/go"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+)
+
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+const (
+	nonceValueSize = 32 // Size of a nonce value in bytes
+	nonceHashSize  = 32 // Size of a Blake3 nonce hash in bytes
+
+	// Bounds on the size of the ZK13 prime p. The group comes from the
+	// address, so it is bounded before any exponentiation.
+	minZKPBits = 256
+	maxZKPBits = 4096
+)
+
+// Address is the serialized form of an address, as produced by the
+// PadawanZero account package. Its JSON encoding matches AddressInfo, which
+// base64 encodes the already encoded key, commitment and nonce fields again.
+type Address struct {
+	PublicKey          string `json:"publicKey"`
+	LocationCommitment string `json:"locationCommitment"`
+	ZKPProof           string `json:"zkpProof"`
+	NonceValue         string
+	NonceHash          string
+}
+
+// MarshalJSON encodes a like AddressInfo.MarshalJSON.
+func (a *Address) MarshalJSON() ([]byte, error) {
+	type Alias Address
+	return json.Marshal(&struct {
+		*Alias
+		PublicKey          string `json:"publicKey"`
+		LocationCommitment string `json:"locationCommitment"`
+		NonceValue         string `json:"nonceValue"`
+		NonceHash          string `json:"nonceHash"`
+	}{
+		Alias:              (*Alias)(a),
+		PublicKey:          base64.StdEncoding.EncodeToString([]byte(a.PublicKey)),
+		LocationCommitment: base64.StdEncoding.EncodeToString([]byte(a.LocationCommitment)),
+		NonceValue:         base64.StdEncoding.EncodeToString([]byte(a.NonceValue)),
+		NonceHash:          base64.StdEncoding.EncodeToString([]byte(a.NonceHash)),
+	})
+}
+
+// UnmarshalJSON decodes the output of AddressInfo.MarshalJSON.
+func (a *Address) UnmarshalJSON(data []byte) error {
+	type Alias Address
+	aux := &struct {
+		*Alias
+		PublicKey          string `json:"publicKey"`
+		LocationCommitment string `json:"locationCommitment"`
+		NonceValue         string `json:"nonceValue"`
+		NonceHash          string `json:"nonceHash"`
+	}{
+		Alias: (*Alias)(a),
+	}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	for _, field := range []struct {
+		name    string
+		encoded string
+		dst     *string
+	}{
+		{"public key", aux.PublicKey, &a.PublicKey},
+		{"location commitment", aux.LocationCommitment, &a.LocationCommitment},
+		{"nonce value", aux.NonceValue, &a.NonceValue},
+		{"nonce hash", aux.NonceHash, &a.NonceHash},
+	} {
+		decoded, err := base64.StdEncoding.DecodeString(field.encoded)
+		if err != nil {
+			return fmt.Errorf("invalid %s encoding: %w", field.name, err)
+		}
+		*field.dst = string(decoded)
+	}
+	return nil
+}
+
+// Check is the outcome of a single verification step.
+type Check struct {
+	Name string
+	Err  error
+}
+
+// Report collects the outcome of every check run against an AddressInfo.
+type Report struct {
+	Checks []Check
+}
+
+// Valid reports whether all checks passed.
+func (r *Report) Valid() bool {
+	return r.Err() == nil
+}
+
+// Err returns the failed checks joined into a single error, or nil.
+func (r *Report) Err() error {
+	var errs []error
+	for _, c := range r.Checks {
+		if c.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name, c.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Verifier checks AddressInfos. It is safe for concurrent use.
+type Verifier struct {
+	suite kyber.Group
+}
+
+// New creates a Verifier.
+func New() *Verifier {
+	return &Verifier{suite: edwards25519.NewBlakeSHA256Ed25519()}
+}
+
+var defaultVerifier = New()
+
+// Verify checks a with the default Verifier.
+func Verify(a *Address) *Report {
+	return defaultVerifier.Verify(a)
+}
+
+// VerifyJSON decodes a JSON AddressInfo and checks it with the default Verifier.
+func VerifyJSON(data []byte) (*Report, error) {
+	return defaultVerifier.VerifyJSON(data)
+}
+
+// VerifyJSON decodes a JSON AddressInfo and checks it.
+func (v *Verifier) VerifyJSON(data []byte) (*Report, error) {
+	a := &Address{}
+	if err := json.Unmarshal(data, a); err != nil {
+		return nil, fmt.Errorf("error decoding address: %w", err)
+	}
+	return v.Verify(a), nil
+}
+
+// ErrMissingAddress is reported when Verify is given a nil Address.
+var ErrMissingAddress = errors.New("missing address")
+
+// Verify checks the key and commitment encodings, the ZKP and the nonce
+// structure of a. A nil a yields a report with a single failed check.
+func (v *Verifier) Verify(a *Address) *Report {
+	if a == nil {
+		return &Report{Checks: []Check{{Name: "address", Err: ErrMissingAddress}}}
+	}
+	return &Report{
+		Checks: []Check{
+			{Name: "public key", Err: v.checkPoint(a.PublicKey)},
+			{Name: "location commitment", Err: v.checkPoint(a.LocationCommitment)},
+			{Name: "zkp", Err: CheckZKPProof(a.ZKPProof, a.PublicKey)},
+			{Name: "nonce", Err: CheckNonce(a.NonceValue, a.NonceHash)},
+		},
+	}
+}
+
+// checkPoint checks that s is a base64 encoded Edwards25519 point.
+func (v *Verifier) checkPoint(s string) error {
+	data, err := base64.RawStdEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("invalid encoding: %w", err)
+	}
+	if len(data) != v.suite.PointLen() {
+		return fmt.Errorf("invalid length %d, expected %d", len(data), v.suite.PointLen())
+	}
+	if err := v.suite.Point().UnmarshalBinary(data); err != nil {
+		return fmt.Errorf("invalid point: %w", err)
+	}
+	return nil
+}
+
+// ParseZKPProof decodes an AddressInfo ZKP proof and checks the size of its
+// group.
+func ParseZKPProof(proof string) (*libzk13.KnowledgeProof, error) {
+	parsed, err := libzk13.ParseKnowledgeProof(proof)
+	if err != nil {
+		return nil, err
+	}
+	if bits := parsed.P.BitLen(); bits < minZKPBits || bits > maxZKPBits {
+		return nil, fmt.Errorf("group size %d bits, expected between %d and %d", bits, minZKPBits, maxZKPBits)
+	}
+	if parsed.Q.BitLen() < minZKPBits/2 {
+		return nil, fmt.Errorf("subgroup size %d bits, expected at least %d", parsed.Q.BitLen(), minZKPBits/2)
+	}
+	return parsed, nil
+}
+
+// CheckZKPProof verifies proof as a proof of knowledge bound to publicKey,
+// the encoded public key of the same address.
+func CheckZKPProof(proof, publicKey string) error {
+	parsed, err := ParseZKPProof(proof)
+	if err != nil {
+		return err
+	}
+	return libzk13.VerifyKnowledge(parsed, []byte(publicKey))
+}
+
+// CheckNonce checks that the nonce value and hash are base64 encoded and of the expected sizes.
+func CheckNonce(value, hash string) error {
+	v, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return fmt.Errorf("invalid value encoding: %w", err)
+	}
+	if len(v) != nonceValueSize {
+		return fmt.Errorf("invalid value length %d, expected %d", len(v), nonceValueSize)
+	}
+
+	h, err := base64.StdEncoding.DecodeString(hash)
+	if err != nil {
+		return fmt.Errorf("invalid hash encoding: %w", err)
+	}
+	if len(h) != nonceHashSize {
+		return fmt.Errorf("invalid hash length %d, expected %d", len(h), nonceHashSize)
+	}
+	return nil
+}
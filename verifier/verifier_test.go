@@ -0,0 +1,115 @@
+package verifier
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/nicksrepo/padawanzero/internal/account"
+	libzk13 "github.com/nicksrepo/padawanzero/zero-knowledge"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateAddress returns a valid address in its verifier form.
+func generateAddress(tb testing.TB) *Address {
+	return generateAddressAt(tb, 40.7128, -74.0060)
+}
+
+func generateAddressAt(tb testing.TB, lat, lon float64) *Address {
+	ai, err := account.GenerateAddress(lat, lon, 256)
+	require.NoError(tb, err)
+	return (*Address)(ai)
+}
+
+func TestVerify(t *testing.T) {
+	ai := generateAddress(t)
+
+	report := Verify(ai)
+	assert.True(t, report.Valid(), report.Err())
+	require.Len(t, report.Checks, 4)
+	assert.Equal(t, "zkp", report.Checks[2].Name)
+}
+
+func TestVerifyForgedProof(t *testing.T) {
+	ai := generateAddress(t)
+
+	// The legacy r|P pair can only be checked by the prover
+	forged := *ai
+	forged.ZKPProof = "deadbeef|cafebabe"
+	assert.False(t, Verify(&forged).Valid())
+
+	// A well-formed proof with a tampered response
+	proof, err := libzk13.ParseKnowledgeProof(ai.ZKPProof)
+	require.NoError(t, err)
+	proof.S.Add(proof.S, big.NewInt(1))
+	forged.ZKPProof = proof.String()
+	assert.ErrorContains(t, Verify(&forged).Err(), "zkp")
+
+	// A valid proof copied onto another address
+	other := generateAddressAt(t, 51.5074, -0.1278)
+	forged = *other
+	forged.ZKPProof = ai.ZKPProof
+	assert.ErrorContains(t, Verify(&forged).Err(), "zkp")
+}
+
+func TestVerifyJSON(t *testing.T) {
+	// Address files are written by the account package, so decode its encoding
+	ai, err := account.GenerateAddress(40.7128, -74.0060, 256)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(ai)
+	require.NoError(t, err)
+
+	report, err := VerifyJSON(data)
+	require.NoError(t, err)
+	assert.True(t, report.Valid(), report.Err())
+
+	// The encodings round trip in both directions
+	a := &Address{}
+	require.NoError(t, json.Unmarshal(data, a))
+	assert.Equal(t, (*Address)(ai), a)
+
+	data, err = json.Marshal(a)
+	require.NoError(t, err)
+	decoded := &account.AddressInfo{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, ai, decoded)
+
+	_, err = VerifyJSON([]byte("invalid"))
+	assert.Error(t, err)
+	_, err = VerifyJSON([]byte(`{"publicKey":"!!"}`))
+	assert.ErrorContains(t, err, "public key")
+}
+
+func TestVerifyInvalid(t *testing.T) {
+	valid := generateAddress(t)
+
+	tests := []struct {
+		name   string
+		modify func(ai *Address)
+		errMsg string
+	}{
+		{"Invalid public key encoding", func(ai *Address) { ai.PublicKey = "!!" }, "public key"},
+		{"Short location commitment", func(ai *Address) { ai.LocationCommitment = "AAAA" }, "location commitment"},
+		{"Missing proof separator", func(ai *Address) { ai.ZKPProof = "abcdef" }, "zkp"},
+		{"Invalid proof hex", func(ai *Address) { ai.ZKPProof = "xyz|2|3|4|5|6" }, "zkp"},
+		{"Tiny proof group", func(ai *Address) { ai.ZKPProof = "3|2|5|1|1|0" }, "group size"},
+		{"Oversized proof group", func(ai *Address) {
+			ai.ZKPProof = strings.Repeat("f", maxZKPBits/4+1) + "|2|3|4|5|6"
+		}, "group size"},
+		{"Short nonce value", func(ai *Address) { ai.NonceValue = "AAAA" }, "nonce"},
+		{"Invalid nonce hash encoding", func(ai *Address) { ai.NonceHash = "!!" }, "nonce"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ai := *valid
+			tt.modify(&ai)
+			report := Verify(&ai)
+			assert.False(t, report.Valid())
+			assert.ErrorContains(t, report.Err(), tt.errMsg)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProveVerifyKnowledge(t *testing.T) {
+	z := NewZK13("secret baggage", 256)
+	context := []byte("address public key")
+
+	proof, err := z.ProveKnowledge(context)
+	require.NoError(t, err)
+	assert.NoError(t, VerifyKnowledge(proof, context))
+
+	// The proof is bound to its context
+	assert.Error(t, VerifyKnowledge(proof, []byte("another key")))
+
+	// The encoding round trips
+	parsed, err := ParseKnowledgeProof(proof.String())
+	require.NoError(t, err)
+	assert.Equal(t, proof, parsed)
+	assert.NoError(t, VerifyKnowledge(parsed, context))
+}
+
+func TestVerifyKnowledgeRejects(t *testing.T) {
+	z := NewZK13("secret baggage", 256)
+	context := []byte("address public key")
+	proof, err := z.ProveKnowledge(context)
+	require.NoError(t, err)
+
+	// Another secret's public value does not match the response
+	other := NewZK13("other baggage", 256)
+	otherProof, err := other.ProveKnowledge(context)
+	require.NoError(t, err)
+
+	one := big.NewInt(1)
+	tests := []struct {
+		name   string
+		modify func(proof *KnowledgeProof)
+	}{
+		{"Tampered response", func(proof *KnowledgeProof) { proof.S = new(big.Int).Add(proof.S, one) }},
+		{"Tampered commitment", func(proof *KnowledgeProof) { proof.R = new(big.Int).Exp(proof.R, big.NewInt(2), proof.P) }},
+		{"Swapped public value", func(proof *KnowledgeProof) { proof.Y = new(big.Int).Exp(proof.G, big.NewInt(12345), proof.P) }},
+		{"Identity values", func(proof *KnowledgeProof) { proof.Y, proof.R, proof.S = one, one, big.NewInt(0) }},
+		{"Response out of range", func(proof *KnowledgeProof) { proof.S = new(big.Int).Add(proof.S, proof.Q) }},
+		{"Foreign group", func(proof *KnowledgeProof) { proof.Params = otherProof.Params }},
+		{"Tiny group", func(proof *KnowledgeProof) {
+			proof.Params = Params{P: big.NewInt(3), G: big.NewInt(2), Q: big.NewInt(5)}
+		}},
+		{"Missing values", func(proof *KnowledgeProof) { proof.S = nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forged := *proof
+			tt.modify(&forged)
+			assert.Error(t, VerifyKnowledge(&forged, context))
+		})
+	}
+
+	assert.Error(t, VerifyKnowledge(nil, context))
+}
+
+func TestParseKnowledgeProof(t *testing.T) {
+	for _, s := range []string{"", "1|2", "1|2|3|4|5|xyz", "1|2|3|4|5|-6"} {
+		_, err := ParseKnowledgeProof(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -19,33 +19,115 @@ func NewZK13(secretBaggage string, bits int) *ZK13 {
 	if bits <= 0 {
 		panic("bits must be positive")
 	}
-	var p *big.Int
-	var err error
-	z := &ZK13{}
-	p, err = GenerateLargePrime(bits)
+	params, err := GenerateParams(bits)
 	if err != nil {
-		panic(fmt.Sprintf("Failed to generate a large prime: %v", err))
+		panic(fmt.Sprintf("Failed to generate group parameters: %v", err))
 	}
+	return &ZK13{
+		p:  params.P,
+		g:  params.G,
+		q:  params.Q,
+		Hs: hashSecret(secretBaggage),
+	}
+}
+
+// GenerateParams generates a prime p of the given bit size such that p-1 has
+// a prime factor q of half that size, and a generator g of the subgroup of
+// order q.
+func GenerateParams(bits int) (Params, error) {
 	q, err := GenerateLargePrime(bits / 2)
 	if err != nil {
-		panic(fmt.Sprintf("Failed to generate a large prime: %v", err))
+		return Params{}, fmt.Errorf("failed to generate a large prime: %w", err)
+	}
+
+	// Search for p = m*q + 1 with an even cofactor m of the remaining size
+	one := big.NewInt(1)
+	cofactorBits := bits - q.BitLen()
+	if cofactorBits < 2 {
+		return Params{}, fmt.Errorf("bits too small: %d", bits)
 	}
+	p := new(big.Int)
+	for {
+		m, err := rand.Int(rand.Reader, new(big.Int).Lsh(one, uint(cofactorBits)))
+		if err != nil {
+			return Params{}, err
+		}
+		m.SetBit(m, cofactorBits-1, 1)
+		m.SetBit(m, 0, 0)
+		p.Mul(m, q).Add(p, one)
+		if p.BitLen() == bits && p.ProbablyPrime(20) {
+			break
+		}
+	}
+
 	g, err := GenerateGenerator(p, q)
 	if err != nil {
-		panic(fmt.Sprintf("Failed to generate a generator: %v", err))
+		return Params{}, fmt.Errorf("failed to generate a generator: %w", err)
+	}
+	return Params{P: p, G: g, Q: q}, nil
+}
+
+// Params holds the public group parameters a verifier needs in order to
+// check proofs produced by a ZK13 instance.
+type Params struct {
+	P, G, Q *big.Int
+}
+
+// Params returns the public group parameters of z.
+func (z *ZK13) Params() Params {
+	return Params{
+		P: new(big.Int).Set(z.p),
+		G: new(big.Int).Set(z.g),
+		Q: new(big.Int).Set(z.q),
+	}
+}
+
+// Validate checks that p and q are prime, that q divides p-1 and that g
+// generates the subgroup of order q. It does not bound the sizes of the
+// values, so callers handling untrusted parameters must do that first.
+func (params Params) Validate() error {
+	if params.P == nil || params.G == nil || params.Q == nil {
+		return fmt.Errorf("incomplete group parameters")
+	}
+	one := big.NewInt(1)
+	if params.G.Cmp(one) <= 0 || params.G.Cmp(params.P) >= 0 {
+		return fmt.Errorf("generator out of range")
+	}
+	if !params.P.ProbablyPrime(20) {
+		return fmt.Errorf("p is not prime")
+	}
+	if !params.Q.ProbablyPrime(20) {
+		return fmt.Errorf("q is not prime")
 	}
-	z.q = q
-	z.g = g
-	z.p = p
-	if !z.ValidateParameters(big.NewInt(224)) {
-		z.p = nil
-		p, err = GenerateLargePrime(bits)
-		z.p = p
+	pMinusOne := new(big.Int).Sub(params.P, one)
+	if new(big.Int).Mod(pMinusOne, params.Q).Sign() != 0 {
+		return fmt.Errorf("q does not divide p-1")
 	}
+	if new(big.Int).Exp(params.G, params.Q, params.P).Cmp(one) != 0 {
+		return fmt.Errorf("generator does not have order q")
+	}
+	return nil
+}
+
+// NewZK13WithParams initializes a ZK13 structure from previously published
+// group parameters instead of generating fresh ones. The parameters are
+// validated first.
+func NewZK13WithParams(secretBaggage string, params Params) (*ZK13, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &ZK13{
+		p:  new(big.Int).Set(params.P),
+		g:  new(big.Int).Set(params.G),
+		q:  new(big.Int).Set(params.Q),
+		Hs: hashSecret(secretBaggage),
+	}, nil
+}
+
+// hashSecret derives Hs from the secret baggage.
+func hashSecret(secretBaggage string) *big.Int {
 	hash := blake3.Sum512([]byte(secretBaggage))
-	Hs := new(big.Int).SetBytes(hash[:])
-	z.Hs = Hs
-	return z
+	return new(big.Int).SetBytes(hash[:])
 }
 
 type Proof struct {
@@ -53,15 +135,15 @@ type Proof struct {
 }
 
 func (z *ZK13) Prover(nonce *big.Int) (*Proof, error) {
-	k, err := rand.Int(rand.Reader, z.p) // Prover's random secret
+	// Prover's random secret in [1, q)
+	k, err := rand.Int(rand.Reader, new(big.Int).Sub(z.q, big.NewInt(1)))
 	if err != nil {
 		return nil, err
 	}
-	r := new(big.Int).Exp(z.g, k, z.p) // r = g^k mod p
-	F := new(big.Int).Mul(z.Hs, k)     // F = Hs*k
-	pMinusOne := new(big.Int).Sub(z.p, big.NewInt(1))
-	F.Mod(F, pMinusOne)                // F = Hs*k mod (p-1)
-	P := new(big.Int).Exp(z.g, F, z.p) // P = g^F mod p
+	k.Add(k, big.NewInt(1))
+	r := z.calculateR(k) // r = g^k mod p
+	F := z.calculateF(k) // F = Hs*k mod q
+	P := z.CalculateP(F) // P = g^F mod p
 	proof := &Proof{
 		R:     r,
 		P:     P,
@@ -70,20 +152,35 @@ func (z *ZK13) Prover(nonce *big.Int) (*Proof, error) {
 	return proof, nil
 }
 
-// Verifier checks if the provided proof (r, P, nonce) is valid  Verifier checks the proof using constant-time comparison
+// Verifier checks if the provided proof (r, P, nonce) is valid.
+// Since P = g^(Hs*k mod q), r = g^k and g has order q, a valid proof
+// satisfies P = r^Hs mod p.
 func (z *ZK13) Verifier(proof *Proof) bool {
-	// Calculate expected value of r
-	expectedR := new(big.Int).Exp(z.g, proof.Nonce, z.p)
-	expectedR.Mul(expectedR, new(big.Int).Exp(z.Hs, proof.R, z.p))
-	expectedR.Mod(expectedR, z.p)
+	if proof == nil || proof.R == nil || proof.P == nil || proof.Nonce == nil {
+		return false
+	}
 
-	// Check that r matches expected value
-	if proof.P.Cmp(expectedR) != 0 {
+	// r must be an element of the subgroup of order q. This rules out r = 0
+	// and r = 1, which satisfy the equation below for every secret, and
+	// small-order elements that only reveal Hs modulo their order.
+	one := big.NewInt(1)
+	if proof.R.Cmp(one) <= 0 || proof.R.Cmp(z.p) >= 0 {
+		return false
+	}
+	if new(big.Int).Exp(proof.R, z.q, z.p).Cmp(one) != 0 {
+		return false
+	}
+
+	// Calculate expected value of P
+	expectedP := new(big.Int).Exp(proof.R, z.Hs, z.p)
+
+	// Check that P matches expected value
+	if proof.P.Cmp(expectedP) != 0 {
 		return false
 	}
 
 	// Check that nonce is valid
-	if proof.Nonce.Cmp(big.NewInt(1)) <= 0 || proof.Nonce.Cmp(z.q) >= 0 {
+	if proof.Nonce.Cmp(one) <= 0 || proof.Nonce.Cmp(z.q) >= 0 {
 		return false
 	}
 
@@ -104,10 +201,9 @@ func (z *ZK13) calculateR(k *big.Int) *big.Int {
 	return new(big.Int).Exp(z.g, k, z.p)
 }
 
-// calculateF calculates F = Hs*k mod (p-1).
+// calculateF calculates F = Hs*k mod q.
 func (z *ZK13) calculateF(k *big.Int) *big.Int {
-	pMinusOne := new(big.Int).Sub(z.p, big.NewInt(1))
-	return new(big.Int).Mod(new(big.Int).Mul(z.Hs, k), pMinusOne)
+	return new(big.Int).Mod(new(big.Int).Mul(z.Hs, k), z.q)
 }
 
 // CalculateP calculates P = g^F mod p.
@@ -158,21 +254,19 @@ type Verifier struct {
 // GenerateGenerator generates a generator of the form g = h^((p-1)/q) where
 // h is a random element in the field and q is a large prime factor of p-1.
 func GenerateGenerator(p, q *big.Int) (*big.Int, error) {
-	// Generate a random element h in the field
-	h, err := rand.Int(rand.Reader, p)
-	if err != nil {
-		return nil, err
-	}
-	// Ensure that h is not a multiple of q
-	for h.Mod(h, q).Cmp(big.NewInt(0)) == 0 {
-		h, err = rand.Int(rand.Reader, p)
+	one := big.NewInt(1)
+	pMinusOne := new(big.Int).Sub(p, one)
+	pMinusOneOverQ := new(big.Int).Div(pMinusOne, q)
+	for {
+		// Generate a random element h in the field
+		h, err := rand.Int(rand.Reader, p)
 		if err != nil {
 			return nil, err
 		}
+		// Compute g = h^((p-1)/q), retrying when it is the identity
+		g := new(big.Int).Exp(h, pMinusOneOverQ, p)
+		if g.Cmp(one) > 0 {
+			return g, nil
+		}
 	}
-	// Compute g = h^((p-1)/q)
-	pMinusOne := new(big.Int).Sub(p, big.NewInt(1))
-	pMinusOneOverQ := new(big.Int).Div(pMinusOne, q)
-	g := new(big.Int).Exp(h, pMinusOneOverQ, p)
-	return g, nil
 }
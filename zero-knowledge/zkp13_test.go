@@ -0,0 +1,121 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProverVerifier(t *testing.T) {
+	z := NewZK13("secret baggage", 256)
+	nonce := big.NewInt(2)
+
+	proof, err := z.Prover(nonce)
+	require.NoError(t, err)
+	assert.True(t, z.Verifier(proof))
+
+	// Proofs are randomized but each one verifies
+	other, err := z.Prover(nonce)
+	require.NoError(t, err)
+	assert.NotEqual(t, proof.R, other.R)
+	assert.True(t, z.Verifier(other))
+}
+
+func TestVerifierRejects(t *testing.T) {
+	z := NewZK13("secret baggage", 256)
+	proof, err := z.Prover(big.NewInt(2))
+	require.NoError(t, err)
+
+	// P must equal r^Hs mod p
+	tampered := *proof
+	tampered.P = new(big.Int).Add(proof.P, big.NewInt(1))
+	assert.False(t, z.Verifier(&tampered))
+
+	tampered = *proof
+	tampered.R = new(big.Int).Add(proof.R, big.NewInt(1))
+	assert.False(t, z.Verifier(&tampered))
+
+	// The nonce must lie in (1, q)
+	for _, nonce := range []*big.Int{big.NewInt(1), z.Params().Q} {
+		tampered = *proof
+		tampered.Nonce = nonce
+		assert.False(t, z.Verifier(&tampered))
+	}
+
+	// Degenerate and small-order values of r satisfy P = r^Hs for any secret
+	params := z.Params()
+	pMinusOne := new(big.Int).Sub(params.P, big.NewInt(1))
+	for _, r := range []*big.Int{big.NewInt(0), big.NewInt(1), pMinusOne, params.P} {
+		forged := &Proof{R: r, P: new(big.Int).Exp(r, z.Hs, params.P), Nonce: big.NewInt(2)}
+		assert.False(t, z.Verifier(forged), "r = %v", r)
+	}
+
+	assert.False(t, z.Verifier(nil))
+	assert.False(t, z.Verifier(&Proof{R: proof.R, P: proof.P}))
+}
+
+func TestGenerateParams(t *testing.T) {
+	params, err := GenerateParams(256)
+	require.NoError(t, err)
+	assert.Equal(t, 256, params.P.BitLen())
+	assert.True(t, params.P.ProbablyPrime(20))
+	assert.True(t, params.Q.ProbablyPrime(20))
+
+	// q divides p-1 and g generates the subgroup of order q
+	pMinusOne := new(big.Int).Sub(params.P, big.NewInt(1))
+	assert.Zero(t, new(big.Int).Mod(pMinusOne, params.Q).Sign())
+	assert.Equal(t, 1, params.G.Cmp(big.NewInt(1)))
+	assert.Zero(t, new(big.Int).Exp(params.G, params.Q, params.P).Cmp(big.NewInt(1)))
+}
+
+func TestNewZK13WithParams(t *testing.T) {
+	z := NewZK13("secret baggage", 256)
+	proof, err := z.Prover(big.NewInt(2))
+	require.NoError(t, err)
+
+	// A verifier rebuilt from the published parameters and the same secret accepts the proof
+	rebuilt, err := NewZK13WithParams("secret baggage", z.Params())
+	require.NoError(t, err)
+	assert.True(t, rebuilt.Verifier(proof))
+
+	// A different secret does not
+	wrong, err := NewZK13WithParams("other baggage", z.Params())
+	require.NoError(t, err)
+	assert.False(t, wrong.Verifier(proof))
+
+	_, err = NewZK13WithParams("secret baggage", Params{})
+	assert.Error(t, err)
+}
+
+func TestParamsValidate(t *testing.T) {
+	valid, err := GenerateParams(256)
+	require.NoError(t, err)
+	require.NoError(t, valid.Validate())
+	other, err := GenerateParams(256)
+	require.NoError(t, err)
+
+	// A tiny group in which r = 1, P = 1 would verify for any secret
+	assert.Error(t, Params{P: big.NewInt(3), G: big.NewInt(2), Q: big.NewInt(5)}.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(params *Params)
+		errMsg string
+	}{
+		{"Generator one", func(params *Params) { params.G = big.NewInt(1) }, "generator out of range"},
+		{"Composite p", func(params *Params) { params.P = new(big.Int).Mul(valid.P, big.NewInt(3)) }, "p is not prime"},
+		{"Composite q", func(params *Params) { params.Q = new(big.Int).Add(valid.Q, big.NewInt(1)) }, "q is not prime"},
+		{"q not dividing p-1", func(params *Params) { params.Q = other.Q }, "q does not divide p-1"},
+		{"Generator of the wrong order", func(params *Params) { params.G = new(big.Int).Sub(valid.P, big.NewInt(1)) }, "order q"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := Params{P: valid.P, G: valid.G, Q: valid.Q}
+			tt.modify(&params)
+			assert.ErrorContains(t, params.Validate(), tt.errMsg)
+		})
+	}
+}